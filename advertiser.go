@@ -4,10 +4,14 @@
 package ra
 
 import (
+	"cmp"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"net"
 	"net/netip"
 	"reflect"
 	"slices"
@@ -18,6 +22,32 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+const (
+	// Interval between the final RAs
+	finalRAInterval = 100 * time.Millisecond
+
+	// Deadline of sending all final RAs. The final RAs are best-effort and
+	// shouldn't block the shutdown for long.
+	finalRATimeout = time.Second
+
+	// Hosts ignore the valid lifetime of the prefix shorter than this
+	// value (RFC4862 Section 5.5.3)
+	minPrefixValidLifetimeUpdate = 2 * time.Hour
+
+	// Maximum random delay before responding to RS (MAX_RA_DELAY_TIME of
+	// RFC4861)
+	maxRADelay = 500 * time.Millisecond
+
+	// Delay before sending the RA advertising the state change (e.g. the
+	// tracked route or the routes in the routing table). The changes
+	// within this delay are advertised together with a single RA.
+	triggeredRADelay = 100 * time.Millisecond
+)
+
+// Reported while the link without the link-layer address has no link-local
+// address and LinkLocalAddress isn't configured
+var errNoLinkLocalAddr = errors.New("no link-local address is assigned to the link without link-layer address (configure LinkLocalAddress to assign one)")
+
 type advertiser struct {
 	logger *slog.Logger
 
@@ -32,15 +62,81 @@ type advertiser struct {
 	stopCh        chan any
 	socketCtor    socketCtor
 	deviceWatcher deviceWatcher
+	routeWatcher  routeWatcher
+
+	// Tracks the withdrawn options. Only accessed from the main loop.
+	deprecation *deprecationTracker
+
+	// Tracks the sequence numbers of the PvD options. Only accessed from
+	// the main loop.
+	pvdSequences *pvdSequenceTracker
+
+	// Signs the RAs when SEND is enabled. Nil otherwise. Only accessed
+	// from the main loop.
+	send *sendSigner
+
+	// The random CGA modifier used when SENDConfig.Modifier is not
+	// specified. Generated once, so that the CGA doesn't change across
+	// the reloads. Only accessed from the main loop.
+	cgaModifier []byte
+
+	// Receives the CPSes while SEND is enabled. Nil otherwise. Only
+	// accessed from the main loop.
+	cpsCh <-chan *cpsMsg
+
+	// Stops the CPS receiver. Only accessed from the main loop.
+	stopCPSReceiver func()
+
+	// The link-local address assigned to the device by LinkLocalAddress.
+	// Invalid while we don't own any address. Only accessed from the main
+	// loop.
+	linkLocalAddr netip.Addr
+
+	// The source address of the RAs when the virtual router is enabled.
+	// Invalid otherwise. Only accessed from the main loop.
+	virtualAddr netip.Addr
+
+	// Whether the route tracked by TrackRoute exists. Only meaningful
+	// when TrackRoute is set. Only accessed from the main loop.
+	routePresent bool
+
+	// Whether the health check passes. Only meaningful when HealthCheck is
+	// set. Only accessed from the main loop.
+	healthy bool
+
+	// The destinations of the routes derived from the routing table by
+	// RouteSource. Only accessed from the main loop.
+	sourcedRoutes []netip.Prefix
+
+	// The global and ULA addresses of the upstream interfaces of the
+	// prefixes by name. Only accessed from the main loop.
+	upstreamAddrs map[string][]deviceAddr
+}
+
+// An internal structure to represent the address change of the upstream
+// interface
+type upstreamUpdate struct {
+	name  string
+	addrs []deviceAddr
 }
 
 // An internal structure to represent RS
 type rsMsg struct {
 	rs   *ndp.RouterSolicitation
 	from netip.Addr
+
+	// The Nonce option echoed back in the SEND signed response. Nil if
+	// the option doesn't exist.
+	nonce *ndp.Nonce
+}
+
+// An internal structure to represent CPS
+type cpsMsg struct {
+	cps  *certPathSolicitation
+	from netip.Addr
 }
 
-func newAdvertiser(initialConfig *InterfaceConfig, ctor socketCtor, devWatcher deviceWatcher, logger *slog.Logger) *advertiser {
+func newAdvertiser(initialConfig *InterfaceConfig, ctor socketCtor, devWatcher deviceWatcher, routeWatcher routeWatcher, logger *slog.Logger) *advertiser {
 	return &advertiser{
 		logger:        logger.With(slog.String("interface", initialConfig.Name)),
 		initialConfig: initialConfig,
@@ -49,28 +145,435 @@ func newAdvertiser(initialConfig *InterfaceConfig, ctor socketCtor, devWatcher d
 		stopCh:        make(chan any),
 		socketCtor:    ctor,
 		deviceWatcher: devWatcher,
+		routeWatcher:  routeWatcher,
+		deprecation:   newDeprecationTracker(),
+		pvdSequences:  newPvDSequenceTracker(),
 	}
 }
 
 func (s *advertiser) createRAMsg(config *InterfaceConfig, deviceState *deviceState) *ndp.RouterAdvertisement {
+	s.expireDeprecation(config)
+	msg := s.createRAHeader(config)
+	options := append(s.createOptions(config, deviceState, msg, false), s.deprecation.options("")...)
+	msg.Options = encodePIOFlags(options, expandPrefixes(config.Prefixes, s.prefixSource(config, deviceState), time.Now()))
+	return msg
+}
+
+// createRAHeader creates the RA message without options
+func createRAHeader(config *InterfaceConfig) *ndp.RouterAdvertisement {
 	return &ndp.RouterAdvertisement{
 		CurrentHopLimit:           uint8(config.CurrentHopLimit),
 		ManagedConfiguration:      config.Managed,
 		OtherConfiguration:        config.Other,
-		RouterSelectionPreference: s.toNDPPreference(config.Preference),
+		MobileIPv6HomeAgent:       config.HomeAgent,
+		RouterSelectionPreference: toNDPPreference(config.Preference),
+		NeighborDiscoveryProxy:    config.NDProxy,
 		RouterLifetime:            time.Duration(config.RouterLifetimeSeconds) * time.Second,
 		ReachableTime:             time.Duration(config.ReachableTimeMilliseconds) * time.Millisecond,
 		RetransmitTimer:           time.Duration(config.RetransmitTimeMilliseconds) * time.Millisecond,
-		Options:                   s.createOptions(config, deviceState),
 	}
 }
 
-func (s *advertiser) createOptions(config *InterfaceConfig, deviceState *deviceState) []ndp.Option {
-	options := []ndp.Option{
-		&ndp.LinkLayerAddress{
-			Direction: ndp.Source,
-			Addr:      deviceState.addr,
-		},
+// createRAHeader creates the RA message without options as it's actually
+// sent. The default router is withdrawn while the tracked route is absent or
+// the health check fails.
+func (s *advertiser) createRAHeader(config *InterfaceConfig) *ndp.RouterAdvertisement {
+	msg := createRAHeader(config)
+	if config.TrackRoute != nil && !s.routePresent {
+		withdrawDefaultRouter(msg, config.TrackRoute.Action)
+	}
+	if config.HealthCheck != nil && !s.healthy {
+		withdrawDefaultRouter(msg, config.HealthCheck.Action)
+	}
+	return msg
+}
+
+// withdrawDefaultRouter modifies the RA header while the tracked route is
+// absent or the health check fails, so that the hosts stop using (or prefer
+// the other routers over) us as the default router. The preference must be
+// medium when the router lifetime is zero (RFC4191 Section 2.2), so the
+// zero lifetime wins when both actions are taken.
+func withdrawDefaultRouter(msg *ndp.RouterAdvertisement, action string) {
+	switch action {
+	case "zero-lifetime":
+		msg.RouterLifetime = 0
+		msg.RouterSelectionPreference = ndp.Medium
+	case "low-preference":
+		if msg.RouterLifetime != 0 {
+			msg.RouterSelectionPreference = ndp.Low
+		}
+	default:
+		// At this point, we should have validated the
+		// configuration. If we haven't, it's a bug.
+		panic("BUG (Please report 🙏): Unknown default router action: " + action)
+	}
+}
+
+// prefixSource returns the addresses the prefixes in the auto mode are
+// derived from
+func (s *advertiser) prefixSource(config *InterfaceConfig, deviceState *deviceState) *prefixSource {
+	return &prefixSource{
+		ifName:    config.Name,
+		addrs:     deviceState.globalAddrs,
+		upstreams: s.upstreamAddrs,
+	}
+}
+
+// usesSelfRDNSS returns true when any RDNSS address is "self"
+func usesSelfRDNSS(config *InterfaceConfig) bool {
+	for _, rdnss := range config.RDNSSes {
+		if slices.Contains(rdnss.Addresses, rdnssSelf) {
+			return true
+		}
+	}
+	return false
+}
+
+// selfAddress returns the address of the interface advertised as the "self"
+// RDNSS. The ULA addresses are preferred since they survive the renumbering,
+// then the global addresses, and then the link-local addresses. Among the
+// link-local addresses, the virtual link-local address is preferred so that
+// the DNS server moves with the router. The deprecated addresses are
+// preferred less, and the smaller address is picked among the equally
+// preferred ones. Returns the invalid address when no address is assigned.
+func (s *advertiser) selfAddress(deviceState *deviceState, now time.Time) netip.Addr {
+	candidates := []netip.Addr{}
+	rank := map[netip.Addr]int{}
+	for _, addr := range deviceState.globalAddrs {
+		a := addr.prefix.Addr()
+		r := 2
+		if ulaPrefix.Contains(a) {
+			r = 0
+		}
+		// Deprecated (the preferred lifetime has expired)
+		if !addr.preferredUntil.IsZero() && !addr.preferredUntil.After(now) {
+			r++
+		}
+		candidates = append(candidates, a)
+		rank[a] = r
+	}
+	if len(candidates) == 0 {
+		if s.virtualAddr.IsValid() && slices.Contains(deviceState.addrs, s.virtualAddr) {
+			return s.virtualAddr
+		}
+		candidates = slices.Clone(deviceState.addrs)
+	}
+	if len(candidates) == 0 {
+		return netip.Addr{}
+	}
+	return slices.MinFunc(candidates, func(a, b netip.Addr) int {
+		if c := cmp.Compare(rank[a], rank[b]); c != 0 {
+			return c
+		}
+		return a.Compare(b)
+	})
+}
+
+// updatePvDSequences bumps the sequence numbers of the PvD options whose
+// content has changed since the last update. The content includes the
+// embedded RA header and the deprecated options, and the withdrawn PvDs
+// carrying the deprecated options are counted as well. zero is the same as
+// createOptions.
+func (s *advertiser) updatePvDSequences(config *InterfaceConfig, header *ndp.RouterAdvertisement, zero bool) {
+	s.pvdSequences.update(s.advertisedPvDs(config), func(pvd *PvDConfig) *ndp.RawOption {
+		return s.marshalPvDOption(pvd, 0, header, zero)
+	})
+}
+
+// advertisedPvDs returns the configured PvDs followed by the withdrawn ones
+// still carrying the deprecated options
+func (s *advertiser) advertisedPvDs(config *InterfaceConfig) []*PvDConfig {
+	return append(slices.Clone(config.PvDs), s.deprecation.pvdsWithdrawn()...)
+}
+
+// marshalPvDOption encodes the PvD option together with its deprecated
+// options. The deprecated options are dropped when they don't fit in the
+// option. When zero is true, the nested options carry zero lifetimes.
+func (s *advertiser) marshalPvDOption(pvd *PvDConfig, seq uint16, header *ndp.RouterAdvertisement, zero bool) *ndp.RawOption {
+	nested := createPvDNestedOptions(pvd)
+	if zero {
+		nested = zeroLifetimes(nested)
+	}
+	deprecated := s.deprecation.options(pvd.FQDN)
+	option, err := marshalPvDOption(pvd, seq, header, append(nested, deprecated...))
+	if errors.Is(err, errPvDOptionTooLong) && len(deprecated) > 0 {
+		s.logger.Warn("Deprecated options don't fit in PvD option. Stop deprecating them.", "pvd", pvd.FQDN)
+		option, err = marshalPvDOption(pvd, seq, header, nested)
+	}
+	if err != nil {
+		// At this point, we should have validated the
+		// configuration. If we haven't, it's a bug.
+		panic("BUG (Please report 🙏): Failed to marshal PvD option: " + err.Error())
+	}
+	return option
+}
+
+// updateSEND prepares the signer of the RAs and the source address for SEND.
+// The raw connection is opened and the CPS receiver is launched only while
+// SEND is enabled. The signer is kept even when the source address can't be
+// set, so that we never fall back to the unsigned RAs.
+func (s *advertiser) updateSEND(ctx context.Context, config *InterfaceConfig, sock socket) error {
+	if config.SEND == nil {
+		s.send = nil
+		s.closeSEND(sock)
+		return sock.setSourceAddr(netip.Addr{})
+	}
+
+	if s.cgaModifier == nil {
+		s.cgaModifier = newCGAModifier()
+	}
+
+	signer, err := newSENDSigner(config.SEND, s.cgaModifier)
+	if err != nil {
+		// At this point, we should have validated the
+		// configuration. If we haven't, it's a bug.
+		panic("BUG (Please report 🙏): Failed to create SEND signer: " + err.Error())
+	}
+	s.send = signer
+
+	if s.cpsCh == nil {
+		if err := sock.openRaw(); err != nil {
+			return fmt.Errorf("cannot open raw socket for SEND: %w", err)
+		}
+		s.cpsCh, s.stopCPSReceiver = s.startCPSReceiver(ctx, sock)
+	}
+
+	return sock.setSourceAddr(signer.addr)
+}
+
+// closeSEND stops the CPS receiver and closes the raw connection. The
+// signer is kept, so that the final RAs are still signed.
+func (s *advertiser) closeSEND(sock socket) {
+	if s.cpsCh == nil {
+		return
+	}
+	s.stopCPSReceiver()
+	s.cpsCh = nil
+	sock.closeRaw()
+}
+
+// startCPSReceiver launches the CPS receiver. The returned function stops
+// the receiver and waits for it to exit, so that the raw connection can be
+// closed safely after that.
+func (s *advertiser) startCPSReceiver(ctx context.Context, sock socket) (<-chan *cpsMsg, func()) {
+	receiverCtx, cancel := context.WithCancel(ctx)
+	cpsCh := make(chan *cpsMsg)
+	done := make(chan any)
+	go func() {
+		defer close(done)
+		for {
+			cps, addr, err := sock.recvCPS(receiverCtx)
+			if err != nil {
+				if receiverCtx.Err() != nil {
+					return
+				}
+				if errors.Is(err, errCPSMalformed) {
+					s.logger.Debug("Dropped invalid CPS", "error", err.Error())
+					continue
+				}
+				s.reportFailing(err)
+				continue
+			}
+			select {
+			case cpsCh <- &cpsMsg{cps: cps, from: addr}:
+			case <-receiverCtx.Done():
+				return
+			}
+		}
+	}()
+	return cpsCh, func() {
+		cancel()
+		<-done
+	}
+}
+
+// updateLinkLocalAddr assigns the link-local address configured by
+// LinkLocalAddress to the device and removes the one we assigned before
+func (s *advertiser) updateLinkLocalAddr(config *InterfaceConfig, devState *deviceState) error {
+	addr := netip.Addr{}
+	if config.LinkLocalAddress != "" {
+		// At this point, we should have validated the
+		// configuration. If we haven't, it's a bug.
+		addr = netip.MustParseAddr(config.LinkLocalAddress)
+	}
+
+	if s.linkLocalAddr.IsValid() && s.linkLocalAddr != addr {
+		if err := s.deviceWatcher.delLinkLocalAddr(config.Name, s.linkLocalAddr); err != nil {
+			return fmt.Errorf("cannot remove link-local address %s: %w", s.linkLocalAddr, err)
+		}
+		s.linkLocalAddr = netip.Addr{}
+	}
+
+	if addr.IsValid() && !slices.Contains(devState.addrs, addr) {
+		if err := s.deviceWatcher.addLinkLocalAddr(config.Name, addr); err != nil {
+			return fmt.Errorf("cannot assign link-local address %s: %w", addr, err)
+		}
+		s.linkLocalAddr = addr
+	}
+
+	return nil
+}
+
+// needsLinkLocalAddr returns true when the device is up, but we must wait for
+// the link-local address. The links without the link-layer address (e.g. tun
+// and WireGuard) may not get the link-local address from the kernel.
+func needsLinkLocalAddr(devState *deviceState) bool {
+	return devState.isUp && len(devState.addr) == 0 && !devState.v6LLAddrAssigned
+}
+
+// prepareLinkLocalAddr assigns the configured link-local address to the
+// device waiting for it. Reports failing when LinkLocalAddress isn't
+// configured since the address may never appear.
+func (s *advertiser) prepareLinkLocalAddr(config *InterfaceConfig, devState *deviceState) {
+	if err := s.updateLinkLocalAddr(config, devState); err != nil {
+		s.reportFailing(err)
+		return
+	}
+	if config.LinkLocalAddress == "" {
+		s.reportFailing(errNoLinkLocalAddr)
+	}
+}
+
+// removeLinkLocalAddr removes the link-local address we assigned. This is
+// best-effort since the device may already be gone.
+func (s *advertiser) removeLinkLocalAddr(name string) {
+	if !s.linkLocalAddr.IsValid() {
+		return
+	}
+	if err := s.deviceWatcher.delLinkLocalAddr(name, s.linkLocalAddr); err != nil {
+		s.logger.Warn("Failed to remove link-local address", "address", s.linkLocalAddr.String(), "error", err.Error())
+	}
+	s.linkLocalAddr = netip.Addr{}
+}
+
+// updateVirtualRouter sets the source address of the RAs for the virtual
+// router
+func (s *advertiser) updateVirtualRouter(config *InterfaceConfig, sock socket) {
+	s.virtualAddr = netip.Addr{}
+	if config.VirtualRouter != nil {
+		// At this point, we should have validated the
+		// configuration. If we haven't, it's a bug.
+		s.virtualAddr = netip.MustParseAddr(config.VirtualRouter.LinkLocalAddress)
+	}
+	sock.setVirtualSourceAddr(s.virtualAddr)
+}
+
+// virtualAddrAssigned returns false when the virtual router is enabled, but
+// the virtual link-local address isn't assigned to the device (yet)
+func (s *advertiser) virtualAddrAssigned(devState *deviceState) bool {
+	return !s.virtualAddr.IsValid() || slices.Contains(devState.addrs, s.virtualAddr)
+}
+
+// sendRA sends the RA to dst. The RA exceeding the link MTU is split into
+// the multiple RAs. When SEND is enabled, each RA is signed and sent as the
+// raw message. The nonce is echoed back in the signed RAs.
+func (s *advertiser) sendRA(ctx context.Context, sock socket, dst netip.Addr, msg *ndp.RouterAdvertisement, nonce *ndp.Nonce, devState *deviceState) error {
+	if !s.virtualAddrAssigned(devState) {
+		return fmt.Errorf("virtual link-local address %s is not assigned to the interface", s.virtualAddr)
+	}
+
+	maxLength := maxRALength(devState.mtu)
+	if s.send != nil && maxLength > 0 {
+		overhead, err := s.send.overhead(nonce)
+		if err != nil {
+			return err
+		}
+		maxLength = max(maxLength-overhead, raHeaderLength)
+	}
+
+	msgs, err := splitRAMsg(msg, maxLength)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range msgs {
+		if s.send == nil {
+			if err := sock.sendRA(ctx, dst, m); err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := s.send.sign(m, dst, nonce, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := sock.sendRaw(ctx, dst, b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// answerCPS sends the CPAs carrying the certification path. The CPS from
+// the unspecified address is answered with the multicast CPAs (RFC3971
+// Section 6.4.2). The CPS is ignored when SEND is disabled.
+func (s *advertiser) answerCPS(ctx context.Context, sock socket, cps *cpsMsg) error {
+	if s.send == nil {
+		return nil
+	}
+	dst := cps.from
+	if dst.WithZone("").IsUnspecified() {
+		dst = netip.IPv6LinkLocalAllNodes()
+	}
+	for _, b := range s.send.certPathAdvertisements(cps.cps) {
+		if err := sock.sendRaw(ctx, dst, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateStateChange bumps the sequence numbers of the changed PvDs,
+// deprecates the withdrawn options, and updates the status after the
+// configuration or the state the options are created from (e.g. the tracked
+// route or the addresses the prefixes are derived from) has changed.
+func (s *advertiser) updateStateChange(config *InterfaceConfig, deviceState *deviceState) {
+	s.updateDeprecation(config, deviceState)
+	s.updatePvDSequences(config, s.createRAHeader(config), false)
+	s.setDerivedPrefixes(config, deviceState)
+}
+
+// updateDeprecation starts deprecating the options withdrawn from the RA
+// since the last update. The PvD contents include the deprecated options,
+// so this must be done before bumping the PvD sequence numbers.
+func (s *advertiser) updateDeprecation(config *InterfaceConfig, deviceState *deviceState) {
+	period := time.Duration(*config.DeprecationPeriodSeconds) * time.Second
+	s.deprecation.update(s.createOptions(config, deviceState, s.createRAHeader(config), false), config.PvDs, period, time.Now())
+	s.reportDeprecatedOptions()
+}
+
+// expireDeprecation stops advertising the deprecated options whose period
+// has ended. The PvDs which carried them get new sequence numbers.
+func (s *advertiser) expireDeprecation(config *InterfaceConfig) {
+	if !s.deprecation.expire(time.Now()) {
+		return
+	}
+	s.reportDeprecatedOptions()
+	s.updatePvDSequences(config, s.createRAHeader(config), false)
+}
+
+// createOptions creates the options of the RA. The header is the RA header
+// sent together, which is embedded in the PvD options. When zero is true, the
+// options nested in the PvD options carry zero lifetimes.
+func (s *advertiser) createOptions(config *InterfaceConfig, deviceState *deviceState, header *ndp.RouterAdvertisement, zero bool) []ndp.Option {
+	options := []ndp.Option{}
+
+	// The virtual router advertises the virtual MAC address instead
+	lladdr := deviceState.addr
+	if config.VirtualRouter != nil {
+		mac, err := net.ParseMAC(config.VirtualRouter.MACAddress)
+		if err != nil {
+			// At this point, we should have validated the
+			// configuration. If we haven't, it's a bug.
+			panic("BUG (Please report 🙏): Failed to parse virtual MAC address: " + err.Error())
+		}
+		lladdr = mac
+	}
+
+	// The links without the link-layer address don't have the option
+	if slla := createSourceLinkLayerAddressOption(lladdr); slla != nil {
+		options = append(options, slla)
 	}
 
 	if config.MTU > 0 {
@@ -79,7 +582,63 @@ func (s *advertiser) createOptions(config *InterfaceConfig, deviceState *deviceS
 		})
 	}
 
-	for _, prefix := range config.Prefixes {
+	if config.ExtensionFlags != 0 {
+		// The option carries the 48-bit flags in the network byte order
+		flags := binary.BigEndian.AppendUint64(nil, uint64(config.ExtensionFlags))
+		options = append(options, &ndp.RAFlagsExtension{
+			Flags: flags[2:],
+		})
+	}
+
+	if config.AdvertisementInterval {
+		options = append(options, marshalAdvertisementIntervalOption(advertisementInterval(config)))
+	}
+
+	if config.HomeAgent {
+		options = append(options, marshalHomeAgentInformationOption(config.HomeAgentPreference, config.HomeAgentLifetimeSeconds))
+	}
+
+	if config.CaptivePortal != "" {
+		options = append(options, &ndp.CaptivePortal{
+			URI: config.CaptivePortal,
+		})
+	}
+
+	options = append(options, createPrefixOptions(expandPrefixes(config.Prefixes, s.prefixSource(config, deviceState), time.Now()))...)
+	options = append(options, createRouteOptions(config.Routes)...)
+	options = append(options, createSourcedRouteOptions(config, s.sourcedRoutes)...)
+	options = append(options, createRDNSSOptions(config.RDNSSes, s.selfAddress(deviceState, time.Now()))...)
+	options = append(options, createDNSSLOptions(config.DNSSLs)...)
+
+	for _, nat64prefix := range config.NAT64Prefixes {
+		options = append(options, &ndp.PREF64{
+			Lifetime: time.Second * time.Duration(*nat64prefix.LifetimeSeconds),
+			Prefix:   netip.MustParsePrefix(nat64prefix.Prefix),
+		})
+	}
+
+	for _, dnr := range config.DNRs {
+		option, err := marshalDNROption(dnr)
+		if err != nil {
+			// At this point, we should have validated the
+			// configuration. If we haven't, it's a bug.
+			panic("BUG (Please report 🙏): Failed to marshal DNR option: " + err.Error())
+		}
+		options = append(options, option)
+	}
+
+	for _, pvd := range s.advertisedPvDs(config) {
+		options = append(options, s.marshalPvDOption(pvd, s.pvdSequences.sequence(pvd.FQDN), header, zero))
+	}
+
+	options = append(options, createRawOptions(config.RawOptions)...)
+
+	return options
+}
+
+func createPrefixOptions(prefixes []*PrefixConfig) []ndp.Option {
+	options := []ndp.Option{}
+	for _, prefix := range prefixes {
 		// At this point, we should have validated the
 		// configuration. If we haven't, it's a bug.
 		p := netip.MustParsePrefix(prefix.Prefix)
@@ -92,50 +651,159 @@ func (s *advertiser) createOptions(config *InterfaceConfig, deviceState *deviceS
 			Prefix:                         p.Addr(),
 		})
 	}
+	return options
+}
 
-	for _, route := range config.Routes {
+func createRouteOptions(routes []*RouteConfig) []ndp.Option {
+	options := []ndp.Option{}
+	for _, route := range routes {
 		// At this point, we should have validated the
 		// configuration. If we haven't, it's a bug.
 		p := netip.MustParsePrefix(route.Prefix)
 		options = append(options, &ndp.RouteInformation{
 			PrefixLength:  uint8(p.Bits()),
-			Preference:    s.toNDPPreference(route.Preference),
+			Preference:    toNDPPreference(route.Preference),
 			RouteLifetime: time.Second * time.Duration(route.LifetimeSeconds),
 			Prefix:        p.Addr(),
 		})
 	}
+	return options
+}
+
+// createSourcedRouteOptions creates the Route Information options for the
+// routes derived from the routing table. The routes configured in Routes are
+// skipped to keep the prefixes unique.
+func createSourcedRouteOptions(config *InterfaceConfig, prefixes []netip.Prefix) []ndp.Option {
+	options := []ndp.Option{}
+	if config.RouteSource == nil {
+		return options
+	}
 
-	for _, rdnss := range config.RDNSSes {
+	configured := map[netip.Prefix]bool{}
+	for _, route := range config.Routes {
+		// At this point, we should have validated the
+		// configuration. If we haven't, it's a bug.
+		configured[netip.MustParsePrefix(route.Prefix).Masked()] = true
+	}
+
+	for _, prefix := range prefixes {
+		if configured[prefix] {
+			continue
+		}
+		options = append(options, &ndp.RouteInformation{
+			PrefixLength:  uint8(prefix.Bits()),
+			Preference:    toNDPPreference(config.RouteSource.Preference),
+			RouteLifetime: time.Second * time.Duration(config.RouteSource.LifetimeSeconds),
+			Prefix:        prefix.Addr(),
+		})
+	}
+
+	return options
+}
+
+// createRDNSSOptions creates the RDNSS options. "self" is replaced with the
+// given address. It is omitted when the address is invalid (e.g. no address
+// is assigned to the interface yet), and so is the option without any
+// address.
+func createRDNSSOptions(rdnsses []*RDNSSConfig, self netip.Addr) []ndp.Option {
+	options := []ndp.Option{}
+	for _, rdnss := range rdnsses {
 		addresses := []netip.Addr{}
 		for _, addr := range rdnss.Addresses {
-			// At this point, we should have validated the
-			// configuration. If we haven't, it's a bug.
-			addresses = append(addresses, netip.MustParseAddr(addr))
+			var a netip.Addr
+			if addr == rdnssSelf {
+				if !self.IsValid() {
+					continue
+				}
+				a = self
+			} else {
+				// At this point, we should have validated the
+				// configuration. If we haven't, it's a bug.
+				a = netip.MustParseAddr(addr)
+			}
+			// "self" may be the same as the other address
+			if !slices.Contains(addresses, a) {
+				addresses = append(addresses, a)
+			}
+		}
+		if len(addresses) == 0 {
+			continue
 		}
 		options = append(options, &ndp.RecursiveDNSServer{
 			Lifetime: time.Second * time.Duration(rdnss.LifetimeSeconds),
 			Servers:  addresses,
 		})
 	}
+	return options
+}
 
-	for _, dnssl := range config.DNSSLs {
+func createDNSSLOptions(dnssls []*DNSSLConfig) []ndp.Option {
+	options := []ndp.Option{}
+	for _, dnssl := range dnssls {
 		options = append(options, &ndp.DNSSearchList{
 			Lifetime:    time.Second * time.Duration(dnssl.LifetimeSeconds),
 			DomainNames: dnssl.DomainNames,
 		})
 	}
+	return options
+}
 
-	for _, nat64prefix := range config.NAT64Prefixes {
-		options = append(options, &ndp.PREF64{
-			Lifetime: time.Second * time.Duration(*nat64prefix.LifetimeSeconds),
-			Prefix:   netip.MustParsePrefix(nat64prefix.Prefix),
-		})
+// createFinalRAMsg creates the RA message sent when the advertisement stops
+// (RFC4861 Section 6.2.5). The options are copied so that modifying them
+// doesn't affect the original message.
+func (s *advertiser) createFinalRAMsg(config *InterfaceConfig, deviceState *deviceState) *ndp.RouterAdvertisement {
+	msg := createRAHeader(config)
+
+	// The preference must be medium when the router lifetime is zero
+	// (RFC4191 Section 2.2).
+	msg.RouterLifetime = 0
+	msg.RouterSelectionPreference = ndp.Medium
+
+	// The embedded RA header and the nested options of the PvDs have
+	// changed. Give them the new sequence numbers (RFC8801 Section 3.1).
+	s.expireDeprecation(config)
+	s.updatePvDSequences(config, msg, config.ZeroLifetimesOnStop)
+
+	options := append(s.createOptions(config, deviceState, msg, config.ZeroLifetimesOnStop), s.deprecation.options("")...)
+	if config.ZeroLifetimesOnStop {
+		options = zeroLifetimes(options)
 	}
+	msg.Options = encodePIOFlags(options, expandPrefixes(config.Prefixes, s.prefixSource(config, deviceState), time.Now()))
 
-	return options
+	return msg
 }
 
-func (s *advertiser) toNDPPreference(preference string) ndp.Preference {
+// zeroLifetimes returns the copy of the options with zero lifetimes. The
+// prefixes are deprecated in the same way as the withdrawn ones.
+func zeroLifetimes(options []ndp.Option) []ndp.Option {
+	ret := []ndp.Option{}
+	for _, option := range options {
+		switch opt := option.(type) {
+		case *ndp.PrefixInformation:
+			option = deprecatePrefixInformation(opt)
+		case *ndp.RouteInformation:
+			o := *opt
+			o.RouteLifetime = 0
+			option = &o
+		case *ndp.RecursiveDNSServer:
+			o := *opt
+			o.Lifetime = 0
+			option = &o
+		case *ndp.DNSSearchList:
+			o := *opt
+			o.Lifetime = 0
+			option = &o
+		case *ndp.PREF64:
+			o := *opt
+			o.Lifetime = 0
+			option = &o
+		}
+		ret = append(ret, option)
+	}
+	return ret
+}
+
+func toNDPPreference(preference string) ndp.Preference {
 	switch preference {
 	case "low":
 		return ndp.Low
@@ -144,7 +812,8 @@ func (s *advertiser) toNDPPreference(preference string) ndp.Preference {
 	case "high":
 		return ndp.High
 	default:
-		s.logger.Warn("Unknown router preference. Using medium.", "preference", preference)
+		// At this point, we should have validated the
+		// configuration. Fallback to medium.
 		return ndp.Medium
 	}
 }
@@ -163,6 +832,13 @@ func (s *advertiser) reportReloading() {
 	s.ifaceStatus.Message = ""
 }
 
+func (s *advertiser) reportBackup() {
+	s.ifaceStatusLock.Lock()
+	defer s.ifaceStatusLock.Unlock()
+	s.ifaceStatus.State = Backup
+	s.ifaceStatus.Message = ""
+}
+
 func (s *advertiser) reportFailing(err error) {
 	s.ifaceStatusLock.Lock()
 	defer s.ifaceStatusLock.Unlock()
@@ -195,6 +871,105 @@ func (s *advertiser) incTxStat(solicited bool) {
 	}
 }
 
+// incInvalidRSStat counts the RS dropped by the socket. Returns false if
+// the error is not about the invalid RS.
+func (s *advertiser) incInvalidRSStat(err error) bool {
+	s.ifaceStatusLock.Lock()
+	defer s.ifaceStatusLock.Unlock()
+	switch {
+	case errors.Is(err, errRSMalformed):
+		s.ifaceStatus.MalformedRS++
+	case errors.Is(err, errRSInvalidHopLimit):
+		s.ifaceStatus.InvalidHopLimitRS++
+	case errors.Is(err, errRSInvalidCode):
+		s.ifaceStatus.InvalidCodeRS++
+	case errors.Is(err, errRSInvalidSLLA):
+		s.ifaceStatus.InvalidSLLARS++
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *advertiser) incRateLimitedRS() {
+	s.ifaceStatusLock.Lock()
+	defer s.ifaceStatusLock.Unlock()
+	s.ifaceStatus.RateLimitedRS++
+}
+
+func (s *advertiser) incCoalescedRS() {
+	s.ifaceStatusLock.Lock()
+	defer s.ifaceStatusLock.Unlock()
+	s.ifaceStatus.CoalescedRS++
+}
+
+func (s *advertiser) reportDeprecatedOptions() {
+	s.ifaceStatusLock.Lock()
+	defer s.ifaceStatusLock.Unlock()
+	s.ifaceStatus.DeprecatedOptions = s.deprecation.status()
+}
+
+func (s *advertiser) setNextUnsolicitedRA(t time.Time) {
+	s.ifaceStatusLock.Lock()
+	defer s.ifaceStatusLock.Unlock()
+	if t.IsZero() {
+		s.ifaceStatus.NextUnsolicitedRA = 0
+	} else {
+		s.ifaceStatus.NextUnsolicitedRA = t.Unix()
+	}
+}
+
+func (s *advertiser) setTrackedRoute(config *InterfaceConfig) {
+	s.ifaceStatusLock.Lock()
+	defer s.ifaceStatusLock.Unlock()
+	switch {
+	case config.TrackRoute == nil:
+		s.ifaceStatus.TrackedRoute = ""
+	case s.routePresent:
+		s.ifaceStatus.TrackedRoute = RoutePresent
+	default:
+		s.ifaceStatus.TrackedRoute = RouteAbsent
+	}
+}
+
+// healthCheckReporter returns the function reporting the result of the
+// health check. The results reported after the context is done are ignored,
+// so that the stopped health check doesn't overwrite the status.
+func (s *advertiser) healthCheckReporter(ctx context.Context) func(*HealthCheckStatus) {
+	return func(status *HealthCheckStatus) {
+		s.ifaceStatusLock.Lock()
+		defer s.ifaceStatusLock.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		s.ifaceStatus.HealthCheck = status.deepCopy()
+	}
+}
+
+func (s *advertiser) setDerivedPrefixes(config *InterfaceConfig, devState *deviceState) {
+	s.ifaceStatusLock.Lock()
+	defer s.ifaceStatusLock.Unlock()
+	s.ifaceStatus.DerivedPrefixes = nil
+	if prefixes := derivedPrefixStatus(config.Prefixes, s.prefixSource(config, devState)); len(prefixes) > 0 {
+		s.ifaceStatus.DerivedPrefixes = prefixes
+	}
+}
+
+func (s *advertiser) setSourcedRoutes() {
+	s.ifaceStatusLock.Lock()
+	defer s.ifaceStatusLock.Unlock()
+	s.ifaceStatus.SourcedRoutes = nil
+	for _, prefix := range s.sourcedRoutes {
+		s.ifaceStatus.SourcedRoutes = append(s.ifaceStatus.SourcedRoutes, prefix.String())
+	}
+}
+
+func (s *advertiser) clearHealthCheck() {
+	s.ifaceStatusLock.Lock()
+	defer s.ifaceStatusLock.Unlock()
+	s.ifaceStatus.HealthCheck = nil
+}
+
 func (s *advertiser) setLastUpdate() {
 	s.ifaceStatusLock.Lock()
 	defer s.ifaceStatusLock.Unlock()
@@ -208,6 +983,47 @@ func (s *advertiser) run(ctx context.Context) {
 	// The current device state
 	devState := deviceState{}
 
+	// The last time we sent multicast RA
+	lastMulticastRA := time.Time{}
+
+	// Rate limiter for the RSes. Created once, so that neither the
+	// reloads nor the device state changes refill the bucket.
+	rsLimiter := newTokenBucket(*config.RSRateLimitPerSecond, config.RSRateLimitBurst, time.Now())
+
+	// The route being tracked and the channel reporting its presence.
+	// The channel is nil while no route is tracked.
+	var trackedRoute *TrackRouteConfig
+	var routeCh <-chan bool
+	cancelRouteWatch := func() {}
+	defer func() { cancelRouteWatch() }()
+
+	// The health check being performed and the channel reporting the
+	// changes of the health state. The channel is nil while no health
+	// check is performed.
+	var healthCheck *HealthCheckConfig
+	var healthCh <-chan bool
+	cancelHealthCheck := func() {}
+	defer func() { cancelHealthCheck() }()
+
+	// The filter of the routes derived from the routing table and the
+	// channel reporting them. The channel is nil while no route is
+	// derived.
+	var sourceFilter *routeFilter
+	var sourcedRouteCh <-chan []netip.Prefix
+	cancelRouteSource := func() {}
+	defer func() { cancelRouteSource() }()
+
+	// The upstream interfaces of the prefixes being watched and the
+	// channel reporting their address changes. The channel is nil while
+	// no upstream interface is watched.
+	upstreams := []string{}
+	var upstreamCh <-chan *upstreamUpdate
+	cancelUpstreamWatch := func() {}
+	defer func() { cancelUpstreamWatch() }()
+
+	// Remove the link-local address we assigned on exit
+	defer func() { s.removeLinkLocalAddr(config.Name) }()
+
 	// Set a timestamp for the first "update"
 	s.setLastUpdate()
 
@@ -225,10 +1041,29 @@ waitDevice:
 		case <-ctx.Done():
 			s.reportStopped(ctx.Err())
 			return
+		case <-s.stopCh:
+			s.reportStopped(nil)
+			return
+		case newConfig := <-s.reloadCh:
+			// The new configuration is applied once the device
+			// is ready. Only the link-local address is needed
+			// before that.
+			config = newConfig
+			s.setLastUpdate()
+			if needsLinkLocalAddr(&devState) {
+				s.prepareLinkLocalAddr(config, &devState)
+			}
 		case dev := <-devCh:
 			// Update the device state
 			devState = dev
 
+			// Wait for the link-local address to appear since we
+			// cannot send the RAs without it
+			if needsLinkLocalAddr(&devState) {
+				s.prepareLinkLocalAddr(config, &devState)
+				continue
+			}
+
 			// If the device is up, mac and link-local address are
 			// assigned, we can proceed with the socket creation
 			if dev.isUp || len(dev.addr) > 0 || dev.v6LLAddrAssigned {
@@ -260,45 +1095,309 @@ waitDevice:
 				if receiverCtx.Err() != nil {
 					return
 				}
+				if s.incInvalidRSStat(err) {
+					s.logger.Debug("Dropped invalid RS", "error", err.Error())
+					continue
+				}
 				s.reportFailing(err)
 				continue
 			}
-			rsCh <- &rsMsg{rs: rs, from: addr}
+			select {
+			case rsCh <- &rsMsg{rs: rs, from: addr, nonce: nonceOption(rs)}:
+			case <-receiverCtx.Done():
+				return
+			}
 		}
 	}()
 
+	// For solicited RA. The sources of the RSes waiting for the response
+	// and the timer to respond to them. The channel is nil while no RS is
+	// pending. The pending RSes are carried over the reloads and answered
+	// with the new configuration.
+	pendingRSes := []*rsMsg{}
+	rsTimer := time.NewTimer(0)
+	rsTimer.Stop()
+	var rsTimerCh <-chan time.Time
+
 	s.reportRunning()
 
 reload:
 	for {
-		// RA message
-		msg := s.createRAMsg(config, &devState)
+		// Prepare the signer, the source address, and the raw
+		// connection of SEND
+		if err := s.updateSEND(receiverCtx, config, sock); err != nil {
+			s.reportFailing(err)
+		}
+
+		// Assign the configured link-local address
+		if err := s.updateLinkLocalAddr(config, &devState); err != nil {
+			s.reportFailing(err)
+		}
+
+		// Set the source address of the virtual router
+		s.updateVirtualRouter(config, sock)
+
+		// (Re)start tracking the route when the target has changed
+		if !reflect.DeepEqual(trackedRoute, config.TrackRoute) {
+			cancelRouteWatch()
+			cancelRouteWatch = func() {}
+			trackedRoute = config.TrackRoute
+			routeCh = nil
+			s.routePresent = false
+			if trackedRoute != nil {
+				var err error
+				routeCh, cancelRouteWatch, err = s.watchRoute(ctx, trackedRoute)
+				if err != nil {
+					s.reportFailing(err)
+				} else {
+					// The current state is already in the
+					// channel. Take it before creating any RA.
+					s.routePresent = <-routeCh
+				}
+			}
+			s.setTrackedRoute(config)
+		}
+
+		// (Re)start watching the routing table when the filter has
+		// changed. The filter depends on the interface index, so the
+		// watch is restarted when the device is recreated as well.
+		var filter *routeFilter
+		if config.RouteSource != nil {
+			filter = newRouteFilter(config.RouteSource, devState.index)
+		}
+		if !reflect.DeepEqual(sourceFilter, filter) {
+			cancelRouteSource()
+			cancelRouteSource = func() {}
+			sourceFilter = filter
+			sourcedRouteCh = nil
+			s.sourcedRoutes = nil
+			if filter != nil {
+				var err error
+				sourcedRouteCh, cancelRouteSource, err = s.watchRoutes(ctx, filter)
+				if err != nil {
+					s.reportFailing(err)
+				} else {
+					// The current routes are already in the
+					// channel. Take them before creating any RA.
+					s.sourcedRoutes = <-sourcedRouteCh
+				}
+			}
+			s.setSourcedRoutes()
+		}
+
+		// (Re)start the health check when its parameters have changed
+		if !reflect.DeepEqual(healthCheck, config.HealthCheck) {
+			cancelHealthCheck()
+			cancelHealthCheck = func() {}
+			s.clearHealthCheck()
+			healthCheck = config.HealthCheck
+			healthCh = nil
+			s.healthy = true
+			if healthCheck != nil {
+				healthCh, cancelHealthCheck = s.startHealthCheck(ctx, config)
+			}
+		}
+
+		// (Re)start watching the upstream interfaces of the prefixes
+		// when they have changed. The prefixes are derived once their
+		// addresses are reported.
+		if names := upstreamInterfaces(config.Prefixes); !slices.Equal(upstreams, names) {
+			cancelUpstreamWatch()
+			cancelUpstreamWatch = func() {}
+			upstreams = names
+			upstreamCh = nil
+			s.upstreamAddrs = map[string][]deviceAddr{}
+			if len(names) > 0 {
+				var err error
+				upstreamCh, cancelUpstreamWatch, err = s.watchUpstreams(ctx, names)
+				if err != nil {
+					s.reportFailing(err)
+				}
+			}
+		}
+
+		// Bump the sequence numbers of the changed PvDs and deprecate
+		// the options withdrawn by the reload before creating any
+		// option. The embedded RA header depends on the state of the
+		// tracked route and the health check, so this must be done
+		// after updating them.
+		s.updateStateChange(config, &devState)
+
+		// The number of unsolicited RAs sent since the last (re)start
+		unsolicitedCount := 0
 
 		// For unsolicited RA
-		ticker := time.NewTicker(time.Duration(config.RAIntervalMilliseconds) * time.Millisecond)
+		timer, nextUnsolicitedRA := s.scheduleUnsolicitedRA(nil, config, unsolicitedCount, lastMulticastRA)
+
+		// Answer the RSes received before the reload
+		rsTimerCh = nil
+		if len(pendingRSes) > 0 {
+			rsTimer.Reset(rand.N(maxRADelay + 1))
+			rsTimerCh = rsTimer.C
+		}
+
+		// Apply the new rate limit without refilling the bucket
+		rsLimiter.setLimit(*config.RSRateLimitPerSecond, config.RSRateLimitBurst, time.Now())
 
 		for {
 			select {
 			case rs := <-rsCh:
-				// Reply to RS
-				//
-				// TODO: Rate limit this to mitigate RS flooding attack
-				err := sock.sendRA(ctx, rs.from, msg)
+				if !acceptRS(config, rs) {
+					s.logger.Debug("Ignored RS", "address", rs.from.String())
+					continue
+				}
+
+				if !rsLimiter.allow(time.Now()) {
+					s.incRateLimitedRS()
+					continue
+				}
+
+				// The response is already scheduled. Answer
+				// them together with a multicast RA. In the
+				// unicast-only mode and the low-power profile,
+				// each of them is answered with a unicast RA
+				// instead.
+				if rsTimerCh != nil {
+					pendingRSes = append(pendingRSes, rs)
+					if !unicastRSReplies(config) {
+						s.incCoalescedRS()
+					}
+					continue
+				}
+
+				// Delay the response randomly to avoid the
+				// synchronization with the other routers
+				// (RFC4861 Section 6.2.6).
+				pendingRSes = append(pendingRSes, rs)
+				rsTimer.Reset(rand.N(maxRADelay + 1))
+				rsTimerCh = rsTimer.C
+			case <-rsTimerCh:
+				now := time.Now()
+
+				// The backup virtual router doesn't answer the
+				// RSes. The master answers them.
+				if !s.virtualAddrAssigned(&devState) {
+					rsTimerCh = nil
+					pendingRSes = pendingRSes[:0]
+					s.reportBackup()
+					continue
+				}
+
+				if unicastRSReplies(config) {
+					s.sendUnicastRSResponses(ctx, sock, config, &devState, pendingRSes)
+
+					// In the low-power profile, the RSes from
+					// the unspecified address are answered with
+					// the multicast RA below. Otherwise, such
+					// hosts wait for the next unsolicited RA for
+					// hours.
+					pendingRSes = slices.DeleteFunc(pendingRSes, func(rs *rsMsg) bool {
+						return config.UnicastOnly || !rs.from.WithZone("").IsUnspecified()
+					})
+					if len(pendingRSes) == 0 {
+						rsTimerCh = nil
+						continue
+					}
+				}
+
+				// The kernel has created the neighbor entry
+				// from the Source Link-Layer Address option
+				// of the RS, so the unicast RA doesn't wait
+				// for the address resolution (RFC4861 Section
+				// 6.2.6).
+				if rs, ok := unicastRSResponse(pendingRSes); ok {
+					rsTimerCh = nil
+					pendingRSes = pendingRSes[:0]
+
+					err := s.sendRA(ctx, sock, rs.from, s.createRAMsg(config, &devState), rs.nonce, &devState)
+					if err != nil {
+						s.reportFailing(err)
+						continue
+					}
+					s.incTxStat(true)
+					s.reportRunning()
+					continue
+				}
+
+				// Multicast RAs must be separated by
+				// MinDelayBetweenRAsMilliseconds. If the
+				// unsolicited RA is sent earlier than that,
+				// it answers the RSes instead.
+				sendAt := now.Add(minDelayRemaining(config, lastMulticastRA, now))
+				if !nextUnsolicitedRA.After(sendAt) {
+					rsTimerCh = nil
+					s.incCoalescedRS()
+					pendingRSes = pendingRSes[:0]
+					continue
+				}
+				if sendAt.After(now) {
+					rsTimer.Reset(sendAt.Sub(now))
+					continue
+				}
+
+				// Echo the nonce back only when we answer a
+				// single RS
+				var nonce *ndp.Nonce
+				if len(pendingRSes) == 1 {
+					nonce = pendingRSes[0].nonce
+				}
+
+				rsTimerCh = nil
+				pendingRSes = pendingRSes[:0]
+
+				err := s.sendRA(ctx, sock, netip.IPv6LinkLocalAllNodes(), s.createRAMsg(config, &devState), nonce, &devState)
 				if err != nil {
 					s.reportFailing(err)
 					continue
 				}
+				lastMulticastRA = now
 				s.incTxStat(true)
 				s.reportRunning()
-			case <-ticker.C:
+			case <-timer.C:
+				// Schedule the next unsolicited RA. We'll send
+				// the RA right after this, so count the interval
+				// from now.
+				unsolicitedCount++
+				_, nextUnsolicitedRA = s.scheduleUnsolicitedRA(timer, config, unsolicitedCount, time.Now())
+
+				// The backup virtual router stays silent
+				if !s.virtualAddrAssigned(&devState) {
+					s.reportBackup()
+					continue
+				}
+
+				// The unsolicited RA answers the RSes waiting
+				// for the multicast response as well
+				if _, ok := unicastRSResponse(pendingRSes); rsTimerCh != nil && !ok && !unicastRSReplies(config) {
+					rsTimer.Stop()
+					rsTimerCh = nil
+					s.incCoalescedRS()
+					pendingRSes = pendingRSes[:0]
+				}
+
 				// Send unsolicited RA
-				err := sock.sendRA(ctx, netip.IPv6LinkLocalAllNodes(), msg)
-				if err != nil {
-					s.reportFailing(err)
+				msg := s.createRAMsg(config, &devState)
+				failed := false
+				for _, dst := range unsolicitedRADestinations(config) {
+					if err := s.sendRA(ctx, sock, dst, msg, nil, &devState); err != nil {
+						s.reportFailing(err)
+						failed = true
+						continue
+					}
+					s.incTxStat(false)
+				}
+				if failed {
 					continue
 				}
-				s.incTxStat(false)
+				if !config.UnicastOnly {
+					lastMulticastRA = time.Now()
+				}
 				s.reportRunning()
+			case cps := <-s.cpsCh:
+				if err := s.answerCPS(ctx, sock, cps); err != nil {
+					s.reportFailing(err)
+					continue
+				}
 			case newConfig := <-s.reloadCh:
 				if reflect.DeepEqual(config, newConfig) {
 					s.logger.Info("No configuration change. Skip reloading.")
@@ -307,10 +1406,15 @@ reload:
 				config = newConfig
 				s.reportReloading()
 				s.setLastUpdate()
+				timer.Stop()
+				rsTimer.Stop()
 				continue reload
 			case dev := <-devCh:
-				// Save the old address for comparison
+				// Save the old addresses for comparison
 				oldAddr := devState.addr
+				oldPrefixes := derivedPrefixStatus(config.Prefixes, s.prefixSource(config, &devState))
+				oldSelfAddr := s.selfAddress(&devState, time.Now())
+				virtualAddrAssigned := s.virtualAddrAssigned(&devState)
 
 				// Update the device state
 				devState = dev
@@ -318,7 +1422,12 @@ reload:
 				// Device is stopped. Stop the advertisement
 				// and wait for the device to be up again.
 				if !devState.isUp {
+					timer.Stop()
+					rsTimer.Stop()
+					s.setNextUnsolicitedRA(time.Time{})
 					cancelReceiver()
+					s.closeSEND(sock)
+					sock.close()
 					s.reportFailing(fmt.Errorf("device is down"))
 					goto waitDevice
 				}
@@ -328,8 +1437,100 @@ reload:
 				// RA message. Reload internally.
 				if !slices.Equal(oldAddr, dev.addr) {
 					s.reportReloading()
+					timer.Stop()
+					rsTimer.Stop()
+					continue reload
+				}
+
+				// The prefixes derived from the addresses or
+				// the address advertised as the "self" RDNSS
+				// have changed. Advertise the new ones and
+				// deprecate the withdrawn ones.
+				if !reflect.DeepEqual(oldPrefixes, derivedPrefixStatus(config.Prefixes, s.prefixSource(config, &devState))) ||
+					(usesSelfRDNSS(config) && oldSelfAddr != s.selfAddress(&devState, time.Now())) {
+					s.updateStateChange(config, &devState)
+					nextUnsolicitedRA = s.scheduleTriggeredRA(timer, config, lastMulticastRA, nextUnsolicitedRA)
+				}
+
+				// The virtual link-local address is removed
+				// (e.g. the router became the VRRP backup).
+				// Stop advertising until it comes back.
+				if virtualAddrAssigned && !s.virtualAddrAssigned(&devState) {
+					s.logger.Info("Virtual link-local address is removed. Stop advertising as the backup.")
+					s.reportBackup()
+					continue
+				}
+
+				// The virtual link-local address is assigned
+				// (e.g. the router became the VRRP master).
+				// Restart the advertisement to send the initial
+				// RAs immediately.
+				if !virtualAddrAssigned && s.virtualAddrAssigned(&devState) {
+					s.reportReloading()
+					timer.Stop()
+					rsTimer.Stop()
 					continue reload
 				}
+			case present := <-routeCh:
+				if present == s.routePresent {
+					continue
+				}
+				s.routePresent = present
+				s.setTrackedRoute(config)
+
+				// The tracked route has appeared or
+				// disappeared. Tell the change to the hosts
+				// immediately.
+				s.updateStateChange(config, &devState)
+				if present {
+					s.logger.Info("Tracked route is present. Advertise the default router.")
+					nextUnsolicitedRA = s.scheduleTriggeredRA(timer, config, lastMulticastRA, nextUnsolicitedRA)
+				} else {
+					s.logger.Info("Tracked route is absent. Withdraw the default router.")
+					nextUnsolicitedRA = s.scheduleWithdrawalRA(timer, config, lastMulticastRA, nextUnsolicitedRA)
+				}
+			case update := <-upstreamCh:
+				oldPrefixes := derivedPrefixStatus(config.Prefixes, s.prefixSource(config, &devState))
+				s.upstreamAddrs[update.name] = update.addrs
+
+				// The prefixes derived from the addresses of
+				// the upstream interface have changed.
+				// Advertise the new prefixes and deprecate the
+				// withdrawn ones.
+				if !reflect.DeepEqual(oldPrefixes, derivedPrefixStatus(config.Prefixes, s.prefixSource(config, &devState))) {
+					s.logger.Info("Prefixes of the upstream interface have changed", "upstream", update.name)
+					s.updateStateChange(config, &devState)
+					nextUnsolicitedRA = s.scheduleTriggeredRA(timer, config, lastMulticastRA, nextUnsolicitedRA)
+				}
+			case routes := <-sourcedRouteCh:
+				if slices.Equal(routes, s.sourcedRoutes) {
+					continue
+				}
+				s.sourcedRoutes = routes
+				s.setSourcedRoutes()
+
+				// The set of the routes has changed. Advertise
+				// the new routes and deprecate the withdrawn
+				// ones immediately.
+				s.logger.Info("Routes in the routing table have changed", "routes", len(routes))
+				s.updateStateChange(config, &devState)
+				nextUnsolicitedRA = s.scheduleTriggeredRA(timer, config, lastMulticastRA, nextUnsolicitedRA)
+			case healthy := <-healthCh:
+				if healthy == s.healthy {
+					continue
+				}
+				s.healthy = healthy
+
+				// The health state has changed. Tell the
+				// change to the hosts immediately.
+				s.updateStateChange(config, &devState)
+				if healthy {
+					s.logger.Info("Health check passed. Advertise the default router.")
+					nextUnsolicitedRA = s.scheduleTriggeredRA(timer, config, lastMulticastRA, nextUnsolicitedRA)
+				} else {
+					s.logger.Warn("Health check failed. Withdraw the default router.")
+					nextUnsolicitedRA = s.scheduleWithdrawalRA(timer, config, lastMulticastRA, nextUnsolicitedRA)
+				}
 			case <-ctx.Done():
 				s.reportStopped(ctx.Err())
 				break reload
@@ -340,10 +1541,293 @@ reload:
 		}
 	}
 
+	s.setNextUnsolicitedRA(time.Time{})
+
+	s.sendFinalRAs(sock, config, &devState)
+
 	cancelReceiver()
+	s.closeSEND(sock)
 	sock.close()
 }
 
+// watchRoute starts watching the route tracked by TrackRoute. The returned
+// function stops the watch.
+func (s *advertiser) watchRoute(ctx context.Context, config *TrackRouteConfig) (<-chan bool, func(), error) {
+	// At this point, we should have validated the configuration. If we
+	// haven't, it's a bug.
+	prefix := netip.MustParsePrefix(config.Prefix).Masked()
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	ch, err := s.routeWatcher.watch(watchCtx, prefix, config.Table)
+	if err != nil {
+		cancel()
+		return nil, func() {}, fmt.Errorf("cannot watch the tracked route: %w", err)
+	}
+
+	return ch, cancel, nil
+}
+
+// watchRoutes starts watching the routes in the routing table matching the
+// filter. The returned function stops the watch.
+func (s *advertiser) watchRoutes(ctx context.Context, filter *routeFilter) (<-chan []netip.Prefix, func(), error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	ch, err := s.routeWatcher.watchRoutes(watchCtx, filter)
+	if err != nil {
+		cancel()
+		return nil, func() {}, fmt.Errorf("cannot watch the routing table: %w", err)
+	}
+	return ch, cancel, nil
+}
+
+// watchUpstreams starts watching the addresses of the upstream interfaces.
+// The returned function stops the watch.
+func (s *advertiser) watchUpstreams(ctx context.Context, names []string) (<-chan *upstreamUpdate, func(), error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	updateCh := make(chan *upstreamUpdate)
+	for _, name := range names {
+		devCh, err := s.deviceWatcher.watch(watchCtx, name)
+		if err != nil {
+			cancel()
+			return nil, func() {}, fmt.Errorf("cannot watch the upstream interface %s: %w", name, err)
+		}
+		go func() {
+			for {
+				select {
+				case <-watchCtx.Done():
+					return
+				case dev, ok := <-devCh:
+					if !ok {
+						return
+					}
+					select {
+					case updateCh <- &upstreamUpdate{name: name, addrs: dev.globalAddrs}:
+					case <-watchCtx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+	return updateCh, cancel, nil
+}
+
+// startHealthCheck starts the health check in the background. The returned
+// function stops the health check.
+func (s *advertiser) startHealthCheck(ctx context.Context, config *InterfaceConfig) (<-chan bool, func()) {
+	checkCtx, cancel := context.WithCancel(ctx)
+	checker := newHealthChecker(config.HealthCheck, config.Name, s.healthCheckReporter(checkCtx))
+	return checker.run(checkCtx), cancel
+}
+
+// sendUnicastRSResponses answers each of the pending RSes with the unicast
+// RA when unicastRSReplies is true. The RSes from the unspecified address are
+// skipped and the ones from the same address are answered once.
+func (s *advertiser) sendUnicastRSResponses(ctx context.Context, sock socket, config *InterfaceConfig, devState *deviceState, pending []*rsMsg) {
+	msg := s.createRAMsg(config, devState)
+	answered := map[netip.Addr]bool{}
+	for _, rs := range pending {
+		if rs.from.WithZone("").IsUnspecified() || answered[rs.from] {
+			continue
+		}
+		answered[rs.from] = true
+
+		if err := s.sendRA(ctx, sock, rs.from, msg, rs.nonce, devState); err != nil {
+			s.reportFailing(err)
+			continue
+		}
+		s.incTxStat(true)
+		s.reportRunning()
+	}
+}
+
+// acceptRS returns false when the RS must be ignored because its source is
+// not in Clients while ClientsOnly is set.
+func acceptRS(config *InterfaceConfig, rs *rsMsg) bool {
+	if !config.ClientsOnly {
+		return true
+	}
+	for _, client := range config.Clients {
+		// At this point, we should have validated the
+		// configuration. If we haven't, it's a bug.
+		if netip.MustParseAddr(client) == rs.from.WithZone("") {
+			return true
+		}
+	}
+	return false
+}
+
+// advertisementInterval returns the maximum interval between the unsolicited
+// multicast RAs in milliseconds advertised with the Advertisement Interval
+// option
+func advertisementInterval(config *InterfaceConfig) int {
+	if config.PowerProfile == "low-power" {
+		return config.LowPowerMulticastRAIntervalSeconds * 1000
+	}
+	return config.MaxRAIntervalMilliseconds
+}
+
+// unicastRSReplies returns true when each RS must be answered with a unicast
+// RA. That is the case in the unicast-only mode and the low-power profile
+// (RFC7772 Section 5.1).
+func unicastRSReplies(config *InterfaceConfig) bool {
+	return config.UnicastOnly || config.PowerProfile == "low-power"
+}
+
+// unsolicitedRADestinations returns the destinations of the unsolicited
+// RAs. That is the all-nodes multicast address, or Clients in the
+// unicast-only mode.
+func unsolicitedRADestinations(config *InterfaceConfig) []netip.Addr {
+	if !config.UnicastOnly {
+		return []netip.Addr{netip.IPv6LinkLocalAllNodes()}
+	}
+	dsts := []netip.Addr{}
+	for _, client := range config.Clients {
+		// At this point, we should have validated the
+		// configuration. If we haven't, it's a bug.
+		dsts = append(dsts, netip.MustParseAddr(client))
+	}
+	return dsts
+}
+
+// unicastRSResponse returns the RS to respond with unicast RA among the
+// pending RSes. We can reply with unicast RA only when there's a single RS
+// from the specified address. Otherwise, we must reply with multicast RA.
+func unicastRSResponse(pending []*rsMsg) (*rsMsg, bool) {
+	if len(pending) != 1 || pending[0].from.WithZone("").IsUnspecified() {
+		return nil, false
+	}
+	return pending[0], true
+}
+
+// sendFinalRAs sends the final RAs so that the hosts stop using this router
+// immediately (RFC4861 Section 6.2.5). The whole process is bounded by
+// finalRATimeout.
+func (s *advertiser) sendFinalRAs(sock socket, config *InterfaceConfig, devState *deviceState) {
+	// The backup virtual router has nothing to withdraw
+	if *config.FinalRACount == 0 || !s.virtualAddrAssigned(devState) {
+		return
+	}
+
+	// The context of the advertiser may be already cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), finalRATimeout)
+	defer cancel()
+
+	msg := s.createFinalRAMsg(config, devState)
+
+	for i := 0; i < *config.FinalRACount; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				s.logger.Warn("Timeout while sending final RAs")
+				return
+			case <-time.After(finalRAInterval):
+			}
+		}
+		for _, dst := range unsolicitedRADestinations(config) {
+			if err := s.sendRA(ctx, sock, dst, msg, nil, devState); err != nil {
+				s.logger.Warn("Failed to send final RA", "error", err.Error())
+				return
+			}
+			s.incTxStat(false)
+		}
+	}
+}
+
+// scheduleUnsolicitedRA (re)arms the timer for the next unsolicited RA and
+// reports the scheduled time. If the timer is nil, it creates a new one.
+// In the low-power profile, the interval is counted from lastMulticastRA.
+// Returns the timer and the scheduled time.
+func (s *advertiser) scheduleUnsolicitedRA(timer *time.Timer, config *InterfaceConfig, sent int, lastMulticastRA time.Time) (*time.Timer, time.Time) {
+	interval := unsolicitedRAInterval(config, sent)
+	if config.PowerProfile == "low-power" {
+		interval = lowPowerRAInterval(config, lastMulticastRA, time.Now())
+	}
+	if timer == nil {
+		timer = time.NewTimer(interval)
+	} else {
+		timer.Reset(interval)
+	}
+	next := time.Now().Add(interval)
+	s.setNextUnsolicitedRA(next)
+	return timer, next
+}
+
+// scheduleTriggeredRA brings the next unsolicited RA forward to advertise the
+// state change. The RA is sent after triggeredRADelay, so that the burst of
+// the changes is advertised with a single RA, and never earlier than
+// MinDelayBetweenRAsMilliseconds since the last multicast RA. The unsolicited
+// RA scheduled earlier than that is kept as is. In the low-power profile, the
+// change waits for the next unsolicited RA not to wake up the sleeping hosts,
+// except the withdrawal of the default router (see scheduleWithdrawalRA).
+func (s *advertiser) scheduleTriggeredRA(timer *time.Timer, config *InterfaceConfig, lastMulticastRA, nextUnsolicitedRA time.Time) time.Time {
+	if config.PowerProfile == "low-power" {
+		return nextUnsolicitedRA
+	}
+	return s.scheduleWithdrawalRA(timer, config, lastMulticastRA, nextUnsolicitedRA)
+}
+
+// scheduleWithdrawalRA is the same as scheduleTriggeredRA, but brings the RA
+// forward in the low-power profile as well. The withdrawal of the default
+// router can't wait for the next unsolicited RA hours later, since the hosts
+// keep sending the packets to the router which can't forward them.
+func (s *advertiser) scheduleWithdrawalRA(timer *time.Timer, config *InterfaceConfig, lastMulticastRA, nextUnsolicitedRA time.Time) time.Time {
+	now := time.Now()
+	next := now.Add(max(triggeredRADelay, minDelayRemaining(config, lastMulticastRA, now)))
+	if !nextUnsolicitedRA.After(next) {
+		return nextUnsolicitedRA
+	}
+	timer.Reset(next.Sub(now))
+	s.setNextUnsolicitedRA(next)
+	return next
+}
+
+// lowPowerRAInterval returns the delay until sending the next unsolicited RA
+// in the low-power profile. The multicast RAs are separated by
+// LowPowerMulticastRAIntervalSeconds even across the reloads, so that the
+// sleeping hosts aren't woken up frequently (RFC7772 Section 5.1). The first
+// RA after the start is sent immediately.
+func lowPowerRAInterval(config *InterfaceConfig, lastMulticastRA, now time.Time) time.Duration {
+	next := lastMulticastRA.Add(time.Duration(config.LowPowerMulticastRAIntervalSeconds) * time.Second)
+	if next.Before(now) {
+		return 0
+	}
+	return next.Sub(now)
+}
+
+// minDelayRemaining returns the delay until MinDelayBetweenRAsMilliseconds
+// passes since lastMulticastRA (MIN_DELAY_BETWEEN_RAS of RFC4861 Section
+// 6.2.6). Returns zero if it has already passed.
+func minDelayRemaining(config *InterfaceConfig, lastMulticastRA, now time.Time) time.Duration {
+	next := lastMulticastRA.Add(time.Duration(*config.MinDelayBetweenRAsMilliseconds) * time.Millisecond)
+	if next.Before(now) {
+		return 0
+	}
+	return next.Sub(now)
+}
+
+// unsolicitedRAInterval returns the delay until sending the next unsolicited
+// RA. sent is the number of unsolicited RAs sent since the advertisement
+// (re)started. As RFC4861 Section 6.2.4 requires, the interval is a random
+// value between MinRAIntervalMilliseconds and MaxRAIntervalMilliseconds.
+// Also, the first few RAs are sent with a shorter interval for the faster
+// convergence.
+func unsolicitedRAInterval(config *InterfaceConfig, sent int) time.Duration {
+	minInterval := time.Duration(config.MinRAIntervalMilliseconds) * time.Millisecond
+	maxInterval := time.Duration(config.MaxRAIntervalMilliseconds) * time.Millisecond
+	interval := minInterval + rand.N(maxInterval-minInterval+1)
+
+	if sent >= *config.InitialRACount {
+		return interval
+	}
+
+	// The first initial RA is sent immediately
+	if sent == 0 {
+		return 0
+	}
+
+	return min(interval, time.Duration(config.InitialRAIntervalMilliseconds)*time.Millisecond)
+}
+
 func (s *advertiser) status() *InterfaceStatus {
 	s.ifaceStatusLock.RLock()
 	defer s.ifaceStatusLock.RUnlock()
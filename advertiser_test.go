@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfAddress(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		virtualAddr string
+		devState    deviceState
+		expected    string
+	}{
+		{
+			name: "ULA over global",
+			devState: deviceState{
+				addrs: []netip.Addr{netip.MustParseAddr("fe80::1")},
+				globalAddrs: []deviceAddr{
+					{prefix: netip.MustParsePrefix("2001:db8::1/64")},
+					{prefix: netip.MustParsePrefix("fd00::2/64")},
+				},
+			},
+			expected: "fd00::2",
+		},
+		{
+			name: "Deprecated under the others",
+			devState: deviceState{
+				globalAddrs: []deviceAddr{
+					{
+						prefix:         netip.MustParsePrefix("fd00::1/64"),
+						validUntil:     now.Add(time.Hour),
+						preferredUntil: now.Add(-time.Second),
+					},
+					{prefix: netip.MustParsePrefix("fd00::2/64")},
+				},
+			},
+			expected: "fd00::2",
+		},
+		{
+			name: "Smaller among equally preferred",
+			devState: deviceState{
+				globalAddrs: []deviceAddr{
+					{prefix: netip.MustParsePrefix("2001:db8::2/64")},
+					{prefix: netip.MustParsePrefix("2001:db8::1/64")},
+				},
+			},
+			expected: "2001:db8::1",
+		},
+		{
+			name: "Link-local without global",
+			devState: deviceState{
+				addrs: []netip.Addr{netip.MustParseAddr("fe80::2"), netip.MustParseAddr("fe80::1")},
+			},
+			expected: "fe80::1",
+		},
+		{
+			name:        "Virtual link-local address",
+			virtualAddr: "fe80::2",
+			devState: deviceState{
+				addrs: []netip.Addr{netip.MustParseAddr("fe80::1"), netip.MustParseAddr("fe80::2")},
+			},
+			expected: "fe80::2",
+		},
+		{
+			name:        "Virtual link-local address not assigned",
+			virtualAddr: "fe80::3",
+			devState: deviceState{
+				addrs: []netip.Addr{netip.MustParseAddr("fe80::2"), netip.MustParseAddr("fe80::1")},
+			},
+			expected: "fe80::1",
+		},
+		{
+			name:     "No address",
+			devState: deviceState{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &advertiser{}
+			if tt.virtualAddr != "" {
+				s.virtualAddr = netip.MustParseAddr(tt.virtualAddr)
+			}
+			addr := s.selfAddress(&tt.devState, now)
+			if tt.expected == "" {
+				require.False(t, addr.IsValid())
+				return
+			}
+			require.Equal(t, netip.MustParseAddr(tt.expected), addr)
+		})
+	}
+}
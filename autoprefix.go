@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"math"
+	"net/netip"
+	"slices"
+	"time"
+)
+
+// ulaPrefix is the Unique Local IPv6 Unicast Address prefix (RFC4193)
+var ulaPrefix = netip.MustParsePrefix("fc00::/7")
+
+// isAutoPrefix returns true when the prefix is ::/N, which advertises the
+// prefixes of the addresses assigned to the interface
+func isAutoPrefix(s string) bool {
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return false
+	}
+	return p.Addr().Is6() && p.Addr().IsUnspecified() && p.Bits() > 0
+}
+
+// derivedPrefix is the prefix derived from the address in the auto mode
+type derivedPrefix struct {
+	config *PrefixConfig
+
+	// The interface and the address the prefix is derived from
+	ifName string
+	addr   deviceAddr
+}
+
+// prefixSource holds the addresses of the interfaces the prefixes in the auto
+// mode are derived from
+type prefixSource struct {
+	// The name and the addresses of the advertising interface
+	ifName string
+	addrs  []deviceAddr
+
+	// The addresses of the upstream interfaces by name
+	upstreams map[string][]deviceAddr
+}
+
+// upstreamInterfaces returns the sorted names of the upstream interfaces of
+// the prefixes
+func upstreamInterfaces(prefixes []*PrefixConfig) []string {
+	names := []string{}
+	for _, prefix := range prefixes {
+		if prefix.UpstreamInterface != "" {
+			names = append(names, prefix.UpstreamInterface)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// expandPrefixes replaces the prefixes in the auto mode with the prefixes
+// derived from the addresses. The derived prefixes come right after the
+// other prefixes in the order of the addresses. The prefixes overlapping the
+// ones configured explicitly or derived earlier are skipped.
+func expandPrefixes(prefixes []*PrefixConfig, source *prefixSource, now time.Time) []*PrefixConfig {
+	ret := []*PrefixConfig{}
+	for _, prefix := range prefixes {
+		if !isAutoPrefix(prefix.Prefix) {
+			ret = append(ret, prefix)
+		}
+	}
+	for _, derived := range derivePrefixes(prefixes, source, now) {
+		ret = append(ret, derived.config)
+	}
+	return ret
+}
+
+// derivedPrefixStatus returns the status of the prefixes derived in the auto
+// mode in order
+func derivedPrefixStatus(prefixes []*PrefixConfig, source *prefixSource) []*DerivedPrefixStatus {
+	ret := []*DerivedPrefixStatus{}
+	for _, derived := range derivePrefixes(prefixes, source, time.Time{}) {
+		ret = append(ret, &DerivedPrefixStatus{
+			Prefix:    derived.config.Prefix,
+			Interface: derived.ifName,
+			Address:   derived.addr.prefix.String(),
+		})
+	}
+	return ret
+}
+
+// derivePrefixes derives the prefixes from the addresses for the prefixes in
+// the auto mode. The derived prefixes overlapping the ones configured
+// explicitly or derived earlier are skipped, so that the advertised prefixes
+// are non-overlapping as the validation requires for the explicit ones.
+func derivePrefixes(prefixes []*PrefixConfig, source *prefixSource, now time.Time) []*derivedPrefix {
+	taken := []netip.Prefix{}
+	for _, prefix := range prefixes {
+		if !isAutoPrefix(prefix.Prefix) {
+			// At this point, we should have validated the
+			// configuration. If we haven't, it's a bug.
+			taken = append(taken, netip.MustParsePrefix(prefix.Prefix).Masked())
+		}
+	}
+
+	ret := []*derivedPrefix{}
+	for _, prefix := range prefixes {
+		if !isAutoPrefix(prefix.Prefix) {
+			continue
+		}
+
+		ifName, addrs := source.ifName, source.addrs
+		if prefix.UpstreamInterface != "" {
+			ifName, addrs = prefix.UpstreamInterface, source.upstreams[prefix.UpstreamInterface]
+		}
+
+		bits := netip.MustParsePrefix(prefix.Prefix).Bits()
+		for _, addr := range addrs {
+			if !autoScopeMatches(prefix.AutoScope, addr.prefix.Addr()) {
+				continue
+			}
+
+			// The prefixes of the advertising interface are
+			// advertised as is. The ones of the upstream
+			// interfaces are sub-netted.
+			var p netip.Prefix
+			if prefix.UpstreamInterface == "" {
+				if addr.prefix.Bits() != bits {
+					continue
+				}
+				p = addr.prefix.Masked()
+			} else {
+				var ok bool
+				if p, ok = subnetPrefix(addr.prefix, bits, prefix.SubnetID); !ok {
+					continue
+				}
+			}
+
+			if slices.ContainsFunc(taken, p.Overlaps) {
+				continue
+			}
+			taken = append(taken, p)
+
+			ret = append(ret, &derivedPrefix{
+				config: derivePrefixConfig(prefix, p, addr, now),
+				ifName: ifName,
+				addr:   addr,
+			})
+		}
+	}
+
+	return ret
+}
+
+// subnetPrefix carves the subnet of the given length with the subnet ID out
+// of the upstream prefix. Returns false when the upstream prefix is longer
+// than the subnet or the subnet ID doesn't fit.
+func subnetPrefix(upstream netip.Prefix, bits int, id int) (netip.Prefix, bool) {
+	upstream = upstream.Masked()
+	if upstream.Bits() > bits {
+		return netip.Prefix{}, false
+	}
+
+	width := bits - upstream.Bits()
+	if width < 63 && id >= 1<<width {
+		return netip.Prefix{}, false
+	}
+
+	// Put the subnet ID into the bits between the upstream prefix and the
+	// subnet
+	a := upstream.Addr().As16()
+	for i := 0; i < width && i < 63; i++ {
+		if (id>>i)&1 == 1 {
+			bit := bits - 1 - i
+			a[bit/8] |= 0x80 >> (bit % 8)
+		}
+	}
+
+	return netip.PrefixFrom(netip.AddrFrom16(a), bits), true
+}
+
+// derivePrefixConfig creates the configuration of the derived prefix from
+// the one in the auto mode. The lifetimes of the prefixes derived from the
+// upstream interface never exceed the ones of the upstream address.
+func derivePrefixConfig(auto *PrefixConfig, p netip.Prefix, addr deviceAddr, now time.Time) *PrefixConfig {
+	derived := auto.deepCopy()
+	derived.Prefix = p.String()
+
+	addrValid := remainingLifetimeSeconds(addr.validUntil, now)
+	addrPreferred := min(remainingLifetimeSeconds(addr.preferredUntil, now), addrValid)
+
+	switch {
+	case auto.UseAddressLifetimes:
+		derived.ValidLifetimeSeconds = &addrValid
+		derived.PreferredLifetimeSeconds = &addrPreferred
+	case auto.UpstreamInterface != "":
+		valid := min(*auto.ValidLifetimeSeconds, addrValid)
+		preferred := min(*auto.PreferredLifetimeSeconds, addrPreferred, valid)
+		derived.ValidLifetimeSeconds = &valid
+		derived.PreferredLifetimeSeconds = &preferred
+	}
+
+	return derived
+}
+
+// remainingLifetimeSeconds returns the lifetime in seconds until the given
+// time for the lifetime fields of the options. The zero time means infinity.
+func remainingLifetimeSeconds(until, now time.Time) int {
+	if until.IsZero() {
+		return math.MaxUint32
+	}
+	if !until.After(now) {
+		return 0
+	}
+	return int(until.Sub(now) / time.Second)
+}
+
+func autoScopeMatches(scope string, addr netip.Addr) bool {
+	switch scope {
+	case "all":
+		return true
+	case "global":
+		return !ulaPrefix.Contains(addr)
+	case "ula":
+		return ulaPrefix.Contains(addr)
+	default:
+		// At this point, we should have validated the
+		// configuration. If we haven't, it's a bug.
+		panic("BUG (Please report 🙏): Unknown auto scope: " + scope)
+	}
+}
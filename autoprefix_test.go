@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+)
+
+func TestDerivePrefixes(t *testing.T) {
+	now := time.Now()
+
+	prefixes := []*PrefixConfig{
+		{
+			Prefix:                   "2001:db8:1::/64",
+			ValidLifetimeSeconds:     ptr.To(2592000),
+			PreferredLifetimeSeconds: ptr.To(604800),
+		},
+		{
+			Prefix:                   "2001:db8:3::/60",
+			ValidLifetimeSeconds:     ptr.To(2592000),
+			PreferredLifetimeSeconds: ptr.To(604800),
+		},
+		{
+			Prefix:                   "::/64",
+			AutoScope:                "all",
+			ValidLifetimeSeconds:     ptr.To(2592000),
+			PreferredLifetimeSeconds: ptr.To(604800),
+		},
+		{
+			Prefix:                   "::/64",
+			AutoScope:                "global",
+			UpstreamInterface:        "wan0",
+			SubnetID:                 1,
+			ValidLifetimeSeconds:     ptr.To(2592000),
+			PreferredLifetimeSeconds: ptr.To(604800),
+		},
+	}
+
+	upstreamAddr := deviceAddr{
+		prefix:         netip.MustParsePrefix("2001:db8:ff00::1/48"),
+		validUntil:     now.Add(time.Second * 1000),
+		preferredUntil: now.Add(time.Second * 500),
+	}
+
+	source := &prefixSource{
+		ifName: "lan0",
+		addrs: []deviceAddr{
+			// Configured explicitly
+			{prefix: netip.MustParsePrefix("2001:db8:1::1/64")},
+			// Inside the prefix configured explicitly
+			{prefix: netip.MustParsePrefix("2001:db8:3:5::1/64")},
+			{prefix: netip.MustParsePrefix("fd00:1::1/64")},
+			// Different length
+			{prefix: netip.MustParsePrefix("2001:db8:2::1/56")},
+		},
+		upstreams: map[string][]deviceAddr{
+			"wan0": {
+				upstreamAddr,
+				// Out of the scope
+				{prefix: netip.MustParsePrefix("fd00:ff::1/48")},
+			},
+		},
+	}
+
+	derived := derivePrefixes(prefixes, source, now)
+	require.Len(t, derived, 2)
+
+	t.Run("Ensure the prefix of the advertising interface is advertised as is", func(t *testing.T) {
+		require.Equal(t, "fd00:1::/64", derived[0].config.Prefix)
+		require.Equal(t, "lan0", derived[0].ifName)
+		require.Equal(t, 2592000, *derived[0].config.ValidLifetimeSeconds)
+		require.Equal(t, 604800, *derived[0].config.PreferredLifetimeSeconds)
+	})
+
+	t.Run("Ensure the prefix of the upstream interface is sub-netted", func(t *testing.T) {
+		require.Equal(t, "2001:db8:ff00:1::/64", derived[1].config.Prefix)
+		require.Equal(t, "wan0", derived[1].ifName)
+		require.Equal(t, upstreamAddr, derived[1].addr)
+		require.Equal(t, 1000, *derived[1].config.ValidLifetimeSeconds)
+		require.Equal(t, 500, *derived[1].config.PreferredLifetimeSeconds)
+	})
+
+	t.Run("Ensure the auto prefix configuration is not modified", func(t *testing.T) {
+		require.Equal(t, "::/64", prefixes[3].Prefix)
+		require.Equal(t, 2592000, *prefixes[3].ValidLifetimeSeconds)
+	})
+}
+
+func TestSubnetPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		upstream string
+		bits     int
+		id       int
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "First subnet",
+			upstream: "2001:db8:0:ff00::/56",
+			bits:     64,
+			id:       0,
+			expected: "2001:db8:0:ff00::/64",
+			ok:       true,
+		},
+		{
+			name:     "Last subnet",
+			upstream: "2001:db8:0:ff00::/56",
+			bits:     64,
+			id:       255,
+			expected: "2001:db8:0:ffff::/64",
+			ok:       true,
+		},
+		{
+			name:     "Upstream address is masked",
+			upstream: "2001:db8:0:ff00::1/56",
+			bits:     64,
+			id:       1,
+			expected: "2001:db8:0:ff01::/64",
+			ok:       true,
+		},
+		{
+			name:     "Same length",
+			upstream: "2001:db8::/64",
+			bits:     64,
+			id:       0,
+			expected: "2001:db8::/64",
+			ok:       true,
+		},
+		{
+			name:     "Subnet ID out of range",
+			upstream: "2001:db8:0:ff00::/56",
+			bits:     64,
+			id:       256,
+			ok:       false,
+		},
+		{
+			name:     "Upstream longer than subnet",
+			upstream: "2001:db8::/64",
+			bits:     56,
+			id:       0,
+			ok:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := subnetPrefix(netip.MustParsePrefix(tt.upstream), tt.bits, tt.id)
+			require.Equal(t, tt.ok, ok)
+			if tt.ok {
+				require.Equal(t, netip.MustParsePrefix(tt.expected), p)
+			}
+		})
+	}
+}
@@ -6,13 +6,19 @@ package ra
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/netip"
+	"net/url"
 	"os"
 	"regexp"
+	"slices"
+	"strconv"
 
 	"github.com/creasty/defaults"
 	"github.com/go-playground/validator/v10"
+	"github.com/mdlayher/ndp"
 	"gopkg.in/yaml.v3"
 )
 
@@ -34,9 +40,125 @@ type InterfaceConfig struct {
 	// compliant with RFC4861. The lower bound is intentionally chosen to
 	// be lower than RFC4861 for faster convergence. If you don't wish to
 	// overwhelm the network, and wish to be compliant with RFC4861, set to
-	// higher than 3000 as RFC4861 suggests.
+	// higher than 3000 as RFC4861 suggests. This value is used as a
+	// default value of MaxRAIntervalMilliseconds.
 	RAIntervalMilliseconds int `yaml:"raIntervalMilliseconds" json:"raIntervalMilliseconds" validate:"required,gte=70,lte=1800000" default:"600000"`
 
+	// Maximum interval between sending unsolicited RA
+	// (MaxRtrAdvInterval of RFC4861). Must be >= 70 and <= 1800000. If
+	// not specified, RAIntervalMilliseconds is used. Each unsolicited RA
+	// is sent after a random interval between MinRAIntervalMilliseconds
+	// and MaxRAIntervalMilliseconds to avoid the synchronization with the
+	// other routers on the same link.
+	MaxRAIntervalMilliseconds int `yaml:"maxRAIntervalMilliseconds" json:"maxRAIntervalMilliseconds" validate:"required,gte=70,lte=1800000"`
+
+	// Minimum interval between sending unsolicited RA
+	// (MinRtrAdvInterval of RFC4861). Must be > 0 and <= 0.75 *
+	// MaxRAIntervalMilliseconds. If not specified, 0.33 *
+	// MaxRAIntervalMilliseconds is used as RFC4861 suggests.
+	MinRAIntervalMilliseconds int `yaml:"minRAIntervalMilliseconds" json:"minRAIntervalMilliseconds" validate:"required,lte_three_quarters MaxRAIntervalMilliseconds"`
+
+	// The number of the initial unsolicited RAs sent with a shorter
+	// interval right after the advertisement starts, the configuration is
+	// reloaded, or the device becomes up again. Must be >= 0 and <= 3.
+	// Default is 3. The upper bound is MAX_INITIAL_RTR_ADVERTISEMENTS of
+	// RFC4861. The first initial RA is sent immediately. If set to zero,
+	// the first unsolicited RA is sent after the regular interval.
+	InitialRACount *int `yaml:"initialRACount" json:"initialRACount" validate:"required,gte=0,lte=3" default:"3"`
+
+	// Interval between sending the initial unsolicited RAs. Must be >= 70
+	// and <= 16000. Default is 16000. The upper bound is
+	// MAX_INITIAL_RTR_ADVERT_INTERVAL of RFC4861. If the regular random
+	// interval is shorter than this value, the regular one is used instead.
+	InitialRAIntervalMilliseconds int `yaml:"initialRAIntervalMilliseconds" json:"initialRAIntervalMilliseconds" validate:"required,gte=70,lte=16000" default:"16000"`
+
+	// The number of the final RAs sent when the advertisement stops
+	// because the interface is removed from the configuration or the
+	// daemon shuts down. The final RAs advertise zero router lifetime so
+	// that the hosts stop using this router immediately. Must be >= 0 and
+	// <= 3. Default is 3. The upper bound is MAX_FINAL_RTR_ADVERTISEMENTS
+	// of RFC4861. If set to zero, no final RA is sent.
+	FinalRACount *int `yaml:"finalRACount" json:"finalRACount" validate:"required,gte=0,lte=3" default:"3"`
+
+	// When set, the final RAs also advertise zero lifetimes for the
+	// routes, RDNSSes, DNSSLs, and NAT64 prefixes, and zero preferred
+	// lifetime for the prefixes, including the ones nested in the PvDs.
+	// The valid lifetime of the prefixes is capped at 2 hours since the
+	// hosts ignore the shorter one (RFC4862 Section 5.5.3). Default is
+	// false.
+	ZeroLifetimesOnStop bool `yaml:"zeroLifetimesOnStop" json:"zeroLifetimesOnStop"`
+
+	// The period in seconds to keep advertising the prefixes, routes,
+	// RDNSS addresses, DNSSL domain names, and NAT64 prefixes removed by
+	// the reload with zero lifetimes so that the hosts stop using them
+	// immediately (RFC4192, RFC9096). The items nested in a PvD are
+	// deprecated within the same PvD, even when the PvD itself is
+	// removed. The valid lifetime of the prefixes is capped at 2 hours
+	// instead of zero since the hosts ignore the shorter one (RFC4862
+	// Section 5.5.3). Must be >= 0 and <= 4294967295. Default is 7200 (2
+	// hours). If set to zero, the removed items are simply omitted.
+	DeprecationPeriodSeconds *int `yaml:"deprecationPeriodSeconds" json:"deprecationPeriodSeconds" validate:"required,gte=0,lte=4294967295" default:"7200"`
+
+	// Minimum interval between the multicast RAs sent in response to RSes
+	// (MIN_DELAY_BETWEEN_RAS of RFC4861). Must be >= 0 and <= 1800000.
+	// Default is 3000. RSes received within this interval are answered
+	// together with a single multicast RA. The RA advertising the state
+	// change (e.g. the tracked route or the health state) is delayed as
+	// well, so that the frequent changes don't flood the link. The
+	// unsolicited RAs, including the first one after the reload, are not
+	// affected. If set to zero, the multicast RAs are not delayed.
+	MinDelayBetweenRAsMilliseconds *int `yaml:"minDelayBetweenRAsMilliseconds" json:"minDelayBetweenRAsMilliseconds" validate:"required,gte=0,lte=1800000" default:"3000"`
+
+	// The number of RSes per second accepted on this interface. The RSes
+	// exceeding the limit are dropped to mitigate the RS flooding attack.
+	// Must be >= 0. Default is 10. If set to zero, the RSes are not rate
+	// limited.
+	RSRateLimitPerSecond *int `yaml:"rsRateLimitPerSecond" json:"rsRateLimitPerSecond" validate:"required,gte=0" default:"10"`
+
+	// The maximum number of RSes accepted at once exceeding
+	// RSRateLimitPerSecond (the size of the token bucket). Must be >= 1.
+	// Default is 10.
+	RSRateLimitBurst int `yaml:"rsRateLimitBurst" json:"rsRateLimitBurst" validate:"required,gte=1" default:"10"`
+
+	// When set, the RAs are never sent to the all-nodes multicast
+	// address. The unsolicited RAs are sent to each address in Clients
+	// and the RSes are answered with the unicast RAs. The RSes from the
+	// unspecified address are ignored since they can't be answered.
+	// Useful for the links where multicast is expensive or unreliable
+	// (e.g. NBMA links or some Wi-Fi networks). Default is false.
+	UnicastOnly bool `yaml:"unicastOnly" json:"unicastOnly"`
+
+	// The link-local unicast addresses of the hosts receiving the
+	// unsolicited RAs when UnicastOnly is set. Must be unique within the
+	// slice.
+	Clients []string `yaml:"clients" json:"clients" validate:"unique,dive,ipv6,link_local_unicast" default:"[]"`
+
+	// When set, the RSes from the addresses not in Clients are ignored.
+	// Clients must not be empty when set. Default is false.
+	ClientsOnly bool `yaml:"clientsOnly" json:"clientsOnly" validate:"clients_only"`
+
+	// The power profile of the advertisement. Must be one of "default"
+	// or "low-power". Default is "default". The "low-power" profile
+	// follows RFC7772 for the links with the battery-powered hosts. The
+	// multicast RAs are sent once in LowPowerMulticastRAIntervalSeconds
+	// regardless of the other interval parameters and the RSes are
+	// answered with the unicast RAs. The RSes from the unspecified
+	// address are answered with the multicast RA since the unicast RA
+	// can't be sent to them. Such multicast RAs are separated by
+	// MinDelayBetweenRAsMilliseconds. The state changes (e.g. the routes
+	// or the prefixes derived from the kernel) wait for the next
+	// multicast RA, except the withdrawal of the default router by
+	// TrackRoute and HealthCheck, which is sent right away. The non-zero
+	// RouterLifetimeSeconds and the non-zero lifetimes of the prefixes
+	// must be >= 3 * LowPowerMulticastRAIntervalSeconds so that the
+	// hosts don't lose them between the RAs.
+	PowerProfile string `yaml:"powerProfile" json:"powerProfile" validate:"oneof=default low-power" default:"default"`
+
+	// The interval between the multicast RAs in the "low-power" profile.
+	// Must be >= 600 and <= 21845. Default is 10800 (3 hours). The upper
+	// bound is a third of the maximum RouterLifetimeSeconds.
+	LowPowerMulticastRAIntervalSeconds int `yaml:"lowPowerMulticastRAIntervalSeconds" json:"lowPowerMulticastRAIntervalSeconds" validate:"required,gte=600,lte=21845" default:"10800"`
+
 	// RA header fields
 
 	// The default value that should be placed in the Hop Count field of
@@ -53,6 +175,44 @@ type InterfaceConfig struct {
 	// configuration information is available via DHCPv6. Default is false.
 	Other bool `yaml:"other" json:"other"`
 
+	// Set H (Home Agent) flag. When set, it indicates that this router
+	// serves as a Mobile IPv6 home agent (RFC6275) and the Home Agent
+	// Information option is advertised. Default is false.
+	HomeAgent bool `yaml:"homeAgent" json:"homeAgent"`
+
+	// The preference of this home agent. The home agent with the higher
+	// value is preferred. Must be >= -32768 and <= 32767. Default is 0.
+	// Only used when HomeAgent is set.
+	HomeAgentPreference int `yaml:"homeAgentPreference" json:"homeAgentPreference" validate:"gte=-32768,lte=32767"`
+
+	// The lifetime of this home agent in seconds. Must be >= 1 and <=
+	// 65535 when HomeAgent is set. Zero is not allowed as RFC6275
+	// requires.
+	HomeAgentLifetimeSeconds int `yaml:"homeAgentLifetimeSeconds" json:"homeAgentLifetimeSeconds" validate:"required_if=HomeAgent true,gte=0,lte=65535"`
+
+	// Set P (Neighbor Discovery Proxy) flag (RFC4389). When set, it
+	// indicates that this router is proxying the Neighbor Discovery
+	// messages. Default is false.
+	NDProxy bool `yaml:"ndProxy" json:"ndProxy"`
+
+	// The 48-bit flags field of the Flags Expansion option (RFC5175) for
+	// the experimental and future flags. The most significant bit
+	// corresponds to the bit 8 and the least significant bit corresponds
+	// to the bit 55 in the RFC5175 numbering. Bits 0-7 are the flags in
+	// the RA header, so they must be set by the dedicated fields. Must be
+	// >= 0 and <= 281474976710655 (0xffffffffffff). The bits not assigned
+	// by IANA are reserved and rejected unless ExperimentalExtensionFlags
+	// is set. No bit of the option is assigned at the moment. The assigned
+	// bits are exposed as the dedicated fields like NDProxy once they are
+	// assigned. Default is 0. If set to zero, the Flags Expansion option
+	// will not be advertised.
+	ExtensionFlags int `yaml:"extensionFlags" json:"extensionFlags" validate:"gte=0,lte=281474976710655,extension_flags"`
+
+	// When set, the reserved bits of ExtensionFlags are allowed for the
+	// experiments. Make sure the hosts on the link don't interpret them
+	// before enabling this. Default is false.
+	ExperimentalExtensionFlags bool `yaml:"experimentalExtensionFlags" json:"experimentalExtensionFlags"`
+
 	// Set Prf (Default Router Preference) field. Must be one of "low",
 	// "medium", or "high". If RouterLifetimeSeconds is 0, it must be set
 	// to "medium". Default is "medium".
@@ -81,6 +241,21 @@ type InterfaceConfig struct {
 	// If set to zero or not specified, MTU opton will not be advertised
 	MTU int `yaml:"mtu" json:"mtu" validate:"gte=0,lte=4294967295"`
 
+	// When set, the Advertisement Interval option (RFC6275) is advertised
+	// with MaxRAIntervalMilliseconds so that the mobile nodes can detect
+	// the movement by the missing RAs. In the "low-power" profile,
+	// LowPowerMulticastRAIntervalSeconds is advertised instead. Default is
+	// false.
+	AdvertisementInterval bool `yaml:"advertisementInterval" json:"advertisementInterval"`
+
+	// The URI of the Captive Portal API (RFC8908) advertised with the
+	// Captive-Portal option (RFC8910). Must be an https URI without the IP
+	// address literal, or "urn:ietf:params:capport:unrestricted" which
+	// indicates there's no captive portal. Must be <= 246 bytes to fit in
+	// the option with the padding. If not specified, Captive-Portal option
+	// will not be advertised.
+	CaptivePortal string `yaml:"captivePortal" json:"captivePortal" validate:"omitempty,max=246,captive_portal"`
+
 	// Prefix-specific configuration parameters. The prefix fields must be
 	// non-overlapping with each other. The slice itself and elements must
 	// not be nil.
@@ -90,6 +265,17 @@ type InterfaceConfig struct {
 	// be the same each other. The slice itself and elements must not be nil.
 	Routes []*RouteConfig `yaml:"routes" json:"routes" validate:"unique=Prefix,dive,required" default:"[]"`
 
+	// Route source configuration parameters. When set, the IPv6 unicast
+	// routes in the kernel routing table matching the filters are
+	// advertised with the Route Information options in addition to
+	// Routes. The routes with the same prefix as the one in Routes are
+	// ignored, so that Routes takes precedence. When the set of the routes
+	// changes, the RA is sent right away and the withdrawn routes are
+	// deprecated in the same way as the ones removed by the reload (see
+	// DeprecationPeriodSeconds). If not specified, no route is derived
+	// from the routing table.
+	RouteSource *RouteSourceConfig `yaml:"routeSource" json:"routeSource"`
+
 	// RDNSS-specific configuration parameters.
 	RDNSSes []*RDNSSConfig `yaml:"rdnsses" json:"rdnsses" validate:"dive,required" default:"[]"`
 
@@ -98,13 +284,105 @@ type InterfaceConfig struct {
 
 	// NAT64 prefix-specific configuration parameters.
 	NAT64Prefixes []*NAT64PrefixConfig `yaml:"nat64prefixes" json:"nat64prefixes" validate:"dive,required" default:"[]"`
+
+	// DNR-specific configuration parameters.
+	DNRs []*DNRConfig `yaml:"dnrs" json:"dnrs" validate:"dive,required" default:"[]"`
+
+	// PvD-specific configuration parameters. The FQDN fields must be
+	// unique within the slice. The slice itself and elements must not be
+	// nil.
+	PvDs []*PvDConfig `yaml:"pvds" json:"pvds" validate:"unique=FQDN,dive,required" default:"[]"`
+
+	// Arbitrary options appended to the RA as is. This is an escape hatch
+	// to advertise the options this daemon doesn't support natively. The
+	// slice itself and elements must not be nil.
+	RawOptions []*RawOptionConfig `yaml:"rawOptions" json:"rawOptions" validate:"dive,required" default:"[]"`
+
+	// The link-local address assigned to the interface by the daemon. This
+	// is needed for the links where the kernel doesn't generate the
+	// link-local address (e.g. tun and WireGuard), since the RAs must be
+	// sent from the link-local address. The address is assigned without
+	// the Duplicate Address Detection and removed when the advertisement
+	// stops. If not specified, the address assigned by the kernel or by
+	// hand is used, and the interface is reported as failing until such
+	// an address appears.
+	LinkLocalAddress string `yaml:"linkLocalAddress" json:"linkLocalAddress" validate:"omitempty,ipv6,link_local_unicast"`
+
+	// SEND-specific configuration parameters. When set, the RAs are
+	// signed with SEcure Neighbor Discovery (RFC3971) and sent from the
+	// CGA (RFC3972) link-local address generated from the key. If not
+	// specified, SEND is disabled.
+	SEND *SENDConfig `yaml:"send" json:"send"`
+
+	// Virtual router configuration parameters for the first-hop
+	// redundancy (e.g. VRRP). When set, the RAs carry the virtual MAC
+	// address in the Source Link-Layer Address option and are sent from
+	// the virtual link-local address, so that the redundant routers look
+	// like a single router. The virtual link-local address must be
+	// assigned to the interface (e.g. the macvlan interface with the
+	// virtual MAC address). While it isn't assigned (e.g. on the backup
+	// router), no RA is sent and the interface is reported as Backup.
+	// Can't be used together with SEND. If not
+	// specified, the address of the interface is used.
+	VirtualRouter *VirtualRouterConfig `yaml:"virtualRouter" json:"virtualRouter" validate:"excluded_with=SEND"`
+
+	// Route tracking configuration parameters. When set, this router is
+	// advertised as the default router only while the tracked route
+	// (e.g. the default route towards the upstream) exists. When the
+	// route disappears or comes back, the RA is sent right away. In the
+	// "low-power" profile, only the disappearance is sent right away and
+	// the comeback waits for the next multicast RA. If not specified,
+	// the route is not tracked.
+	TrackRoute *TrackRouteConfig `yaml:"trackRoute" json:"trackRoute"`
+
+	// Health check configuration parameters. When set, this router is
+	// advertised as the default router only while the health check
+	// passes. When the health state changes, the RA is sent right away.
+	// In the "low-power" profile, only the failure is sent right away and
+	// the recovery waits for the next multicast RA. If not specified, no
+	// health check is performed.
+	HealthCheck *HealthCheckConfig `yaml:"healthCheck" json:"healthCheck"`
 }
 
 // PrefixConfig represents the prefix-specific configuration parameters
 type PrefixConfig struct {
-	// Required: Prefix. Must be a valid IPv6 prefix.
+	// Required: Prefix. Must be a valid IPv6 prefix. If set to ::/N (e.g.
+	// ::/64), the prefixes of length N of the global and ULA addresses
+	// assigned to the interface are advertised instead (auto mode). The
+	// other parameters apply to each of them, and the ones overlapping the
+	// prefixes configured explicitly are skipped. The auto mode can't be
+	// used in PvDs.
 	Prefix string `yaml:"prefix" json:"prefix" validate:"required,cidrv6"`
 
+	// The scope of the prefixes advertised in the auto mode. Must be one
+	// of "all", "global", or "ula". "global" excludes the ULA prefixes
+	// (fc00::/7) and "ula" only includes them. Default is "all". Ignored
+	// unless Prefix is ::/N.
+	AutoScope string `yaml:"autoScope" json:"autoScope" validate:"oneof=all global ula" default:"all"`
+
+	// Advertise the remaining valid and preferred lifetimes of the
+	// address instead of ValidLifetimeSeconds and
+	// PreferredLifetimeSeconds in the auto mode. Useful when the address
+	// comes from the upstream (e.g. DHCPv6-PD) with the finite lifetimes.
+	// Can only be set when Prefix is ::/N. Default is false.
+	UseAddressLifetimes bool `yaml:"useAddressLifetimes" json:"useAddressLifetimes" validate:"auto_prefix_only"`
+
+	// The upstream interface to take the prefixes from in the auto mode
+	// (e.g. the WAN interface of CPE). When set, the prefixes are derived
+	// from the global and ULA addresses of this interface with the prefix
+	// length <= N instead of the ones of the advertising interface. Each
+	// of them is sub-netted to /N with SubnetID. The lifetimes never
+	// exceed the remaining lifetimes of the upstream address. Can only be
+	// set when Prefix is ::/N.
+	UpstreamInterface string `yaml:"upstreamInterface" json:"upstreamInterface" validate:"auto_prefix_only"`
+
+	// The subnet ID put into the bits between the upstream prefix and
+	// /N. For example, the subnet ID 1 makes 2001:db8:0:1::/64 out of
+	// 2001:db8::/56. Must be >= 0. The prefix is not advertised when the
+	// subnet ID doesn't fit in the bits. Can only be set together with
+	// UpstreamInterface. Default is 0.
+	SubnetID int `yaml:"subnetID" json:"subnetID" validate:"gte=0,excluded_without=UpstreamInterface"`
+
 	// Set L (On-Link) flag. When set, it indicates that this prefix can be
 	// used for on-link determination. Default is false.
 	OnLink bool `yaml:"onLink" json:"onLink"`
@@ -114,6 +392,13 @@ type PrefixConfig struct {
 	// Default is false.
 	Autonomous bool `yaml:"autonomous" json:"autonomous"`
 
+	// Set P (DHCPv6-PD Preferred) flag (RFC9762). When set, it indicates
+	// that the hosts should request a delegated prefix with DHCPv6 Prefix
+	// Delegation instead of using this prefix for stateless address
+	// autoconfiguration. Can't be set for the link-local prefix. Default
+	// is false.
+	DHCPv6PDPreferred bool `yaml:"dhcpv6PDPreferred" json:"dhcpv6PDPreferred" validate:"dhcpv6_pd_preferred"`
+
 	// The valid lifetime of the prefix in seconds. Must be >= 0 and <=
 	// 4294967295 and must be >= PreferredLifetimeSeconds. Default is
 	// 2592000 (30 days). If set to 4294967295, it indicates infinity.
@@ -141,6 +426,37 @@ type RouteConfig struct {
 	Preference string `yaml:"preference" json:"preference" validate:"oneof=low medium high" default:"medium"`
 }
 
+// RouteSourceConfig represents the configuration parameters to derive the
+// routes from the kernel routing table. The link-local and multicast routes
+// and the routes through the advertising interface are never advertised.
+type RouteSourceConfig struct {
+	// The ID of the routing table to watch. Must be >= 1 and <=
+	// 4294967295. Default is 254 (main table).
+	Table int `yaml:"table" json:"table" validate:"required,gte=1,lte=4294967295" default:"254"`
+
+	// The protocols of the routes to advertise. Each element must be
+	// one of the well-known protocol names ("kernel", "boot", "static",
+	// "ra", "dhcp", "zebra", "bird", "babel", "bgp", "isis", "ospf",
+	// "rip", or "eigrp") or the protocol number (0-255). If empty, the
+	// routes of any protocol are advertised.
+	Protocols []string `yaml:"protocols" json:"protocols" validate:"unique,dive,route_protocol" default:"[]"`
+
+	// The prefixes limiting the routes to advertise. Each element must be
+	// a valid IPv6 prefix. Only the routes within one of the prefixes are
+	// advertised. If empty, the routes to any destination are advertised.
+	Prefixes []string `yaml:"prefixes" json:"prefixes" validate:"dive,cidrv6" default:"[]"`
+
+	// The lifetime of the routes in seconds. Must be >= 1 and <=
+	// 4294967295. If set to 4294967295, it indicates infinity. Default is
+	// 1800.
+	LifetimeSeconds int `yaml:"lifetimeSeconds" json:"lifetimeSeconds" validate:"required,gte=1,lte=4294967295" default:"1800"`
+
+	// Set Prf (Route Preference) field of the routes. The same as
+	// RouteConfig.Preference. Must be one of "low", "medium", or "high".
+	// Default is "medium".
+	Preference string `yaml:"preference" json:"preference" validate:"oneof=low medium high" default:"medium"`
+}
+
 // RDNSSConfig represents the RDNSS-specific configuration parameters
 type RDNSSConfig struct {
 	// Required: The maximum time in seconds over which these RDNSS
@@ -148,9 +464,18 @@ type RDNSSConfig struct {
 	LifetimeSeconds int `yaml:"lifetimeSeconds" json:"lifetimeSeconds" validate:"required,gte=0,lte=4294967295"`
 
 	// Required: The addresses of the RDNSS servers. You must specify at least one address.
-	Addresses []string `yaml:"addresses" json:"addresses" validate:"required,unique,min=1,dive,ipv6"`
+	// Each element must be an IPv6 address or "self". "self" is the
+	// address of the advertising interface (e.g. when the DNS forwarder
+	// runs on this router). It is resolved to the ULA, global, or
+	// link-local address of the interface in this order of preference,
+	// so the RDNSS option follows the renumbering. The deprecated
+	// addresses are avoided. "self" can't be used in PvDs.
+	Addresses []string `yaml:"addresses" json:"addresses" validate:"required,unique,min=1"`
 }
 
+// The special RDNSS address resolved to the address of the interface
+const rdnssSelf = "self"
+
 // DNSSLConfig represents the DNSSL-specific configuration parameters
 type DNSSLConfig struct {
 	// Required: The maximum time in seconds over which these DNSSL domain
@@ -174,9 +499,224 @@ type NAT64PrefixConfig struct {
 	LifetimeSeconds *int `yaml:"lifetimeSeconds" json:"lifetimeSeconds" validate:"required,gte=0,lte=65528" default:"65528"`
 }
 
+// DNRConfig represents the DNR (Discovery of Network-designated Resolvers,
+// RFC9463) specific configuration parameters. Each DNRConfig is advertised
+// as an Encrypted DNS option. The encoded option must fit in 248 bytes.
+type DNRConfig struct {
+	// Required: The priority of this resolver. The resolver with the lower
+	// value is preferred. Must be >= 1 and <= 65535.
+	ServicePriority int `yaml:"servicePriority" json:"servicePriority" validate:"required,gte=1,lte=65535"`
+
+	// Required: The maximum time in seconds over which this resolver may
+	// be used for name resolution. Must be >= 0 and <= 4294967295. If set
+	// to 4294967295, it indicates infinity.
+	LifetimeSeconds int `yaml:"lifetimeSeconds" json:"lifetimeSeconds" validate:"required,gte=0,lte=4294967295"`
+
+	// Required: The authentication domain name of the resolver used to
+	// authenticate it with TLS.
+	AuthenticationDomainName string `yaml:"authenticationDomainName" json:"authenticationDomainName" validate:"required,domain"`
+
+	// Required: The addresses of the resolver. You must specify at least
+	// one address.
+	Addresses []string `yaml:"addresses" json:"addresses" validate:"required,unique,min=1,dive,ipv6"`
+
+	// Required: The ALPN protocol IDs supported by the resolver. For
+	// example, "dot" for DNS over TLS, "h2" or "h3" for DNS over HTTPS, and
+	// "doq" for DNS over QUIC. You must specify at least one ID.
+	ALPNs []string `yaml:"alpns" json:"alpns" validate:"required,unique,min=1,dive,min=1,max=255"`
+
+	// The port number of the resolver. Must be >= 0 and <= 65535. If set
+	// to zero or not specified, the default port of the protocol is used.
+	Port int `yaml:"port" json:"port" validate:"gte=0,lte=65535"`
+}
+
+// PvDConfig represents the PvD (Provisioning Domain, RFC8801) specific
+// configuration parameters. Each PvDConfig is advertised as a PvD option
+// which contains the options belonging to the PvD. The encoded option must
+// fit in 248 bytes.
+type PvDConfig struct {
+	// Required: The PvD ID FQDN which identifies the PvD.
+	FQDN string `yaml:"fqdn" json:"fqdn" validate:"required,domain"`
+
+	// Set H (HTTP) flag. When set, it indicates that the additional
+	// information of the PvD is available via HTTPS (RFC8801 Section 4).
+	// Default is false.
+	HTTP bool `yaml:"http" json:"http"`
+
+	// Set L (Legacy) flag. When set, it indicates that the PvD is
+	// associated with the IPv4 information provided by DHCPv4. Default is
+	// false.
+	Legacy bool `yaml:"legacy" json:"legacy"`
+
+	// Set R (Router Advertisement) flag. When set, the header of the RA is
+	// included in the PvD option and its parameters apply to the PvD.
+	// Default is false.
+	RAHeader bool `yaml:"raHeader" json:"raHeader"`
+
+	// The delay factor of fetching the additional information. The hosts
+	// wait for the random time between 0 and 2**(Delay * 2) milliseconds
+	// before fetching it. Must be >= 0 and <= 15. Default is 0.
+	Delay int `yaml:"delay" json:"delay" validate:"gte=0,lte=15"`
+
+	// The initial sequence number of the PvD option. Must be >= 0 and <=
+	// 65535. Default is 0. The sequence number is automatically
+	// incremented whenever the content of the PvD option changes on
+	// reload. Changing this value resets the sequence number.
+	SequenceNumber int `yaml:"sequenceNumber" json:"sequenceNumber" validate:"gte=0,lte=65535"`
+
+	// Prefix-specific configuration parameters of the PvD. The same
+	// constraints as InterfaceConfig.Prefixes apply. Additionally, the
+	// auto mode (::/N) can't be used.
+	Prefixes []*PrefixConfig `yaml:"prefixes" json:"prefixes" validate:"non_overlapping_prefix,no_auto_prefix,dive,required" default:"[]"`
+
+	// Route-specific configuration parameters of the PvD. The same
+	// constraints as InterfaceConfig.Routes apply.
+	Routes []*RouteConfig `yaml:"routes" json:"routes" validate:"unique=Prefix,dive,required" default:"[]"`
+
+	// RDNSS-specific configuration parameters of the PvD. The addresses
+	// can't be "self".
+	RDNSSes []*RDNSSConfig `yaml:"rdnsses" json:"rdnsses" validate:"no_self_rdnss,dive,required" default:"[]"`
+
+	// DNSSL-specific configuration parameters of the PvD.
+	DNSSLs []*DNSSLConfig `yaml:"dnssls" json:"dnssls" validate:"dive,required" default:"[]"`
+}
+
+// RawOptionConfig represents the option advertised as is. Exactly one of Hex
+// or Base64 must be specified for the payload. The payload is the option
+// excluding the Type and Length fields, so its length plus two must be the
+// multiple of 8 and must be <= 248 bytes.
+type RawOptionConfig struct {
+	// Required: The option type. Must be >= 1 and <= 255. The types
+	// advertised by this daemon natively (e.g. Prefix Information, RDNSS,
+	// or PvD) and Target Link-Layer Address are not allowed.
+	Type int `yaml:"type" json:"type" validate:"required,gte=1,lte=255,raw_option_type"`
+
+	// The hex-encoded payload of the option.
+	Hex string `yaml:"hex" json:"hex" validate:"required_without=Base64,excluded_with=Base64"`
+
+	// The base64-encoded (standard encoding with padding) payload of the
+	// option.
+	Base64 string `yaml:"base64" json:"base64" validate:"required_without=Hex,excluded_with=Hex"`
+}
+
+// SENDConfig represents the SEND (SEcure Neighbor Discovery, RFC3971)
+// specific configuration parameters
+type SENDConfig struct {
+	// Required: The PEM-encoded RSA private key of the router (PKCS #1 or
+	// PKCS #8). The key size must be >= 1024 and <= 4096 bits. It is used
+	// to generate the CGA and to sign the RAs.
+	PrivateKey string `yaml:"privateKey" json:"privateKey" validate:"required,rsa_private_key"`
+
+	// Required: The PEM-encoded X.509 certificates of the router's
+	// certification path. The first one must be the router's certificate
+	// for PrivateKey, followed by the intermediate ones towards the trust
+	// anchor. They are advertised with the Certification Path
+	// Advertisement messages. You must specify at least one certificate.
+	Certificates []string `yaml:"certificates" json:"certificates" validate:"required,min=1,dive,x509_certificate"`
+
+	// The Sec parameter of the CGA (RFC3972 Section 2) which determines
+	// the security level of the address. Must be >= 0 and <= 1. Default
+	// is 0. The higher value requires exponentially more computation to
+	// generate the address.
+	Sec int `yaml:"sec" json:"sec" validate:"gte=0,lte=1"`
+
+	// The initial modifier of the CGA (RFC3972 Section 4) in 32
+	// hexadecimal digits. It should be a random value unique to the
+	// router (e.g. generated by `openssl rand -hex 16`), so that the
+	// routers sharing the key don't generate the same address. If not
+	// specified, a random modifier is generated when SEND is enabled. It
+	// is kept across the reloads, but the address changes when the
+	// daemon restarts.
+	Modifier string `yaml:"modifier" json:"modifier" validate:"omitempty,cga_modifier"`
+}
+
+// VirtualRouterConfig represents the virtual router specific configuration
+// parameters
+type VirtualRouterConfig struct {
+	// Required: The virtual MAC address advertised in the Source
+	// Link-Layer Address option (e.g. 00:00:5e:00:02:01 for IPv6 VRRP
+	// with VRID 1, RFC5798 Section 7.3).
+	MACAddress string `yaml:"macAddress" json:"macAddress" validate:"required,mac"`
+
+	// Required: The virtual link-local address used as the source
+	// address of the RAs.
+	LinkLocalAddress string `yaml:"linkLocalAddress" json:"linkLocalAddress" validate:"required,ipv6,link_local_unicast"`
+}
+
+// TrackRouteConfig represents the route tracking specific configuration
+// parameters
+type TrackRouteConfig struct {
+	// Required: The destination prefix of the tracked route in CIDR
+	// notation (e.g. ::/0 or 0.0.0.0/0 for the default route). Only the
+	// unicast routes are tracked. The routes whose next hops are all down
+	// (e.g. through the link down) are treated as absent.
+	Prefix string `yaml:"prefix" json:"prefix" validate:"required,cidr"`
+
+	// The ID of the routing table containing the tracked route. Must be
+	// >= 1 and <= 4294967295. Default is 254 (main table).
+	Table int `yaml:"table" json:"table" validate:"required,gte=1,lte=4294967295" default:"254"`
+
+	// The action taken while the tracked route doesn't exist. Must be
+	// one of "zero-lifetime" or "low-preference". Default is
+	// "zero-lifetime". With "zero-lifetime", the RAs advertise zero
+	// router lifetime, so that the hosts stop using this router as the
+	// default router. With "low-preference", the RAs advertise the low
+	// default router preference (RFC4191), so that the hosts prefer the
+	// other routers.
+	Action string `yaml:"action" json:"action" validate:"oneof=zero-lifetime low-preference" default:"zero-lifetime"`
+}
+
+// HealthCheckConfig represents the health check specific configuration
+// parameters
+type HealthCheckConfig struct {
+	// Required: The type of the probe. Must be one of "tcp", "icmp", or
+	// "exec". "tcp" connects to Target over TCP. "icmp" sends an ICMPv6
+	// echo request to Target. "exec" runs Command. The probe succeeds
+	// when the connection is established, the echo reply is received, or
+	// the command exits with status 0 within TimeoutMilliseconds.
+	Type string `yaml:"type" json:"type" validate:"required,oneof=tcp icmp exec"`
+
+	// The target of the probe. Required for "tcp" and "icmp", and must
+	// not be set for "exec". For "tcp", must be a host and port pair
+	// (e.g. [2001:db8::1]:80). For "icmp", must be an IPv6 address. The
+	// link-local address is reached through the advertising interface.
+	Target string `yaml:"target" json:"target" validate:"required_unless=Type exec,excluded_if=Type exec,health_check_target"`
+
+	// The command run by the "exec" probe. The first element is the path
+	// of the executable and the rest are the arguments. Required for
+	// "exec", and must not be set for the other types.
+	Command []string `yaml:"command" json:"command" validate:"required_if=Type exec,excluded_unless=Type exec"`
+
+	// Interval between the probes in milliseconds. Must be >= 100 and <=
+	// 3600000. Default is 1000.
+	IntervalMilliseconds int `yaml:"intervalMilliseconds" json:"intervalMilliseconds" validate:"required,gte=100,lte=3600000" default:"1000"`
+
+	// Timeout of each probe in milliseconds. Must be >= 1 and <=
+	// IntervalMilliseconds. Default is 1000.
+	TimeoutMilliseconds int `yaml:"timeoutMilliseconds" json:"timeoutMilliseconds" validate:"required,gte=1,ltefield=IntervalMilliseconds" default:"1000"`
+
+	// Number of the consecutive successful probes required to consider
+	// the unhealthy router healthy again. Must be >= 1. Default is 2.
+	Rise int `yaml:"rise" json:"rise" validate:"required,gte=1" default:"2"`
+
+	// Number of the consecutive failed probes required to consider the
+	// healthy router unhealthy. The router is considered healthy when
+	// the health check starts. Must be >= 1. Default is 3.
+	Fall int `yaml:"fall" json:"fall" validate:"required,gte=1" default:"3"`
+
+	// The action taken while the router is unhealthy. Must be one of
+	// "zero-lifetime" or "low-preference". Default is "zero-lifetime".
+	// See TrackRouteConfig.Action for the details.
+	Action string `yaml:"action" json:"action" validate:"oneof=zero-lifetime low-preference" default:"zero-lifetime"`
+}
+
 // ValidationErrors is a type alias for the validator.ValidationErrors
 type ValidationErrors = validator.ValidationErrors
 
+// The bits of the Flags Expansion option assigned by IANA in the same
+// numbering as ExtensionFlags. No bit is assigned at the moment.
+const assignedExtensionFlags uint64 = 0
+
 // Regular expression to validate the domain name in DNSSL configuration
 var domainRegexp = regexp.MustCompile(`^(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z0-9][a-z0-9-]{0,61}[a-z0-9]$`)
 
@@ -185,6 +725,20 @@ func (c *Config) defaultAndValidate() error {
 		panic("BUG (Please report 🙏): Defaulting failed: " + err.Error())
 	}
 
+	// Set the default values depending on the other fields
+	for _, iface := range c.Interfaces {
+		if iface == nil {
+			// The validator will report this later
+			continue
+		}
+		if iface.MaxRAIntervalMilliseconds == 0 {
+			iface.MaxRAIntervalMilliseconds = iface.RAIntervalMilliseconds
+		}
+		if iface.MinRAIntervalMilliseconds == 0 {
+			iface.MinRAIntervalMilliseconds = iface.MaxRAIntervalMilliseconds * 33 / 100
+		}
+	}
+
 	validate := validator.New(validator.WithRequiredStructEnabled())
 
 	// Adhoc custom validator which validates the Prefix fields are non-overlapping with each other.
@@ -227,12 +781,67 @@ func (c *Config) defaultAndValidate() error {
 		return true
 	})
 
+	// Adhoc custom validator which validates the value of this field must
+	// be less than or equal to 0.75 * MaxRAIntervalMilliseconds.
+	validate.RegisterValidation("lte_three_quarters MaxRAIntervalMilliseconds", func(fl validator.FieldLevel) bool {
+		minInterval := fl.Field().Int()
+		maxInterval := fl.Parent().FieldByName("MaxRAIntervalMilliseconds").Int()
+		return minInterval*4 <= maxInterval*3
+	})
+
 	// Adhoc custom validator which validates the string is a valid domain name.
 	validate.RegisterValidation("domain", func(fl validator.FieldLevel) bool {
 		dom := fl.Field().String()
 		return domainRegexp.Match([]byte(dom))
 	})
 
+	// Adhoc custom validator which validates the reserved bits of the
+	// Flags Expansion option are not set unless the experimental flags are
+	// allowed.
+	validate.RegisterValidation("extension_flags", func(fl validator.FieldLevel) bool {
+		if fl.Parent().FieldByName("ExperimentalExtensionFlags").Bool() {
+			return true
+		}
+		return uint64(fl.Field().Int())&^assignedExtensionFlags == 0
+	})
+
+	// Adhoc custom validator which validates the string is a valid
+	// Captive Portal API URI.
+	validate.RegisterValidation("captive_portal", func(fl validator.FieldLevel) bool {
+		uri := fl.Field().String()
+		if uri == ndp.Unrestricted {
+			return true
+		}
+		// This rejects the IP address literals in the path
+		if _, err := ndp.NewCaptivePortal(uri); err != nil {
+			return false
+		}
+		u, err := url.Parse(uri)
+		if err != nil {
+			return false
+		}
+		// The host must not be an IP address literal either
+		if _, err := netip.ParseAddr(u.Hostname()); err == nil {
+			return false
+		}
+		return u.Scheme == "https" && u.Host != ""
+	})
+
+	// Adhoc custom validator which validates the P flag is not set for
+	// the link-local prefix. The P flag is meaningless for it since the
+	// link-local addresses are never delegated.
+	validate.RegisterValidation("dhcpv6_pd_preferred", func(fl validator.FieldLevel) bool {
+		if !fl.Field().Bool() {
+			return true
+		}
+		prefix, err := netip.ParsePrefix(fl.Parent().FieldByName("Prefix").String())
+		if err != nil {
+			// Reported by the other validation
+			return true
+		}
+		return !netip.MustParsePrefix("fe80::/10").Overlaps(prefix)
+	})
+
 	// Adhoc custom validator which validates the prefix length must
 	// be one of /32, /40, /48, /56, /64, or /96.
 	validate.RegisterValidation("invalid_prefix_len", func(fl validator.FieldLevel) bool {
@@ -248,6 +857,244 @@ func (c *Config) defaultAndValidate() error {
 		return validPrefixLengths[p.Bits()]
 	})
 
+	// Adhoc custom validator which validates the DNR option doesn't
+	// exceed the maximum length.
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		dnr := sl.Current().Interface().(DNRConfig)
+		if _, err := marshalDNROption(&dnr); errors.Is(err, errDNROptionTooLong) {
+			sl.ReportError(dnr, "DNRConfig", "DNRConfig", "dnr_option_too_long", "")
+		}
+	}, DNRConfig{})
+
+	// Adhoc custom validator which validates the PvD option doesn't
+	// exceed the maximum length. The length of the RA header doesn't
+	// depend on the parameters, so we can use an empty one.
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		pvd := sl.Current().Interface().(PvDConfig)
+		if !pvd.isMarshalable() {
+			return
+		}
+		if _, err := marshalPvDOption(&pvd, 0, &ndp.RouterAdvertisement{}, createPvDNestedOptions(&pvd)); errors.Is(err, errPvDOptionTooLong) {
+			sl.ReportError(pvd, "PvDConfig", "PvDConfig", "pvd_option_too_long", "")
+		}
+	}, PvDConfig{})
+
+	// Adhoc custom validator which validates the address is a link-local
+	// unicast address
+	validate.RegisterValidation("link_local_unicast", func(fl validator.FieldLevel) bool {
+		addr, err := netip.ParseAddr(fl.Field().String())
+		return err == nil && addr.IsLinkLocalUnicast()
+	})
+
+	// Adhoc custom validator which validates Clients is not empty when
+	// ClientsOnly is set
+	validate.RegisterValidation("clients_only", func(fl validator.FieldLevel) bool {
+		return !fl.Field().Bool() || fl.Parent().FieldByName("Clients").Len() > 0
+	})
+
+	// Adhoc custom validator which validates the lifetimes are long
+	// enough for the multicast RA interval of the low-power profile
+	// (RFC7772 Section 5.1). The minimum lifetime is reported as the
+	// parameter.
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		iface := sl.Current().Interface().(InterfaceConfig)
+		if iface.PowerProfile != "low-power" {
+			return
+		}
+
+		minLifetime := iface.LowPowerMulticastRAIntervalSeconds * 3
+		param := strconv.Itoa(minLifetime)
+
+		if iface.RouterLifetimeSeconds != 0 && iface.RouterLifetimeSeconds < minLifetime {
+			sl.ReportError(iface.RouterLifetimeSeconds, "RouterLifetimeSeconds", "RouterLifetimeSeconds", "low_power_lifetime", param)
+		}
+
+		checkPrefixes := func(ns string, prefixes []*PrefixConfig) {
+			for i, prefix := range prefixes {
+				if prefix == nil {
+					// Reported by the other validation
+					continue
+				}
+				for _, lifetime := range []struct {
+					name  string
+					value *int
+				}{
+					{"ValidLifetimeSeconds", prefix.ValidLifetimeSeconds},
+					{"PreferredLifetimeSeconds", prefix.PreferredLifetimeSeconds},
+				} {
+					if lifetime.value == nil || *lifetime.value == 0 || *lifetime.value >= minLifetime {
+						continue
+					}
+					field := fmt.Sprintf("%sPrefixes[%d].%s", ns, i, lifetime.name)
+					sl.ReportError(*lifetime.value, field, field, "low_power_lifetime", param)
+				}
+			}
+		}
+
+		checkPrefixes("", iface.Prefixes)
+		for i, pvd := range iface.PvDs {
+			if pvd != nil {
+				checkPrefixes(fmt.Sprintf("PvDs[%d].", i), pvd.Prefixes)
+			}
+		}
+	}, InterfaceConfig{})
+
+	// Adhoc custom validator which validates the raw option type is not
+	// the one advertised natively
+	validate.RegisterValidation("raw_option_type", func(fl validator.FieldLevel) bool {
+		return !nativeOptionTypes[int(fl.Field().Int())]
+	})
+
+	// Adhoc custom validator which validates the payload of the raw
+	// option can be decoded, is aligned to 8 bytes, and fits in the
+	// option
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		raw := sl.Current().Interface().(RawOptionConfig)
+		if raw.Hex == "" && raw.Base64 == "" {
+			// Reported by the other validations
+			return
+		}
+		field := "Hex"
+		if raw.Base64 != "" {
+			field = "Base64"
+		}
+		value, err := raw.value()
+		if err != nil {
+			sl.ReportError(raw, field, field, "raw_option_encoding", "")
+			return
+		}
+		if (len(value)+2)%8 != 0 {
+			sl.ReportError(value, field, field, "raw_option_alignment", "")
+		}
+		if len(value)+2 > maxRawOptionLength {
+			sl.ReportError(value, field, field, "raw_option_too_long", "")
+		}
+	}, RawOptionConfig{})
+
+	// Adhoc custom validator which validates the PEM-encoded RSA
+	// private key for SEND
+	validate.RegisterValidation("rsa_private_key", func(fl validator.FieldLevel) bool {
+		key, err := parseRSAPrivateKey(fl.Field().String())
+		return err == nil && key.N.BitLen() >= minSENDKeyBits && key.N.BitLen() <= maxSENDKeyBits
+	})
+
+	// Adhoc custom validator which validates the hex-encoded CGA
+	// modifier
+	validate.RegisterValidation("cga_modifier", func(fl validator.FieldLevel) bool {
+		_, err := parseCGAModifier(fl.Field().String())
+		return err == nil
+	})
+
+	// Adhoc custom validator which validates the PEM-encoded X.509
+	// certificate
+	validate.RegisterValidation("x509_certificate", func(fl validator.FieldLevel) bool {
+		_, err := parseCertificate(fl.Field().String())
+		return err == nil
+	})
+
+	// Adhoc custom validator which validates the router's certificate is
+	// for the private key
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		send := sl.Current().Interface().(SENDConfig)
+		key, err := parseRSAPrivateKey(send.PrivateKey)
+		if err != nil || len(send.Certificates) == 0 {
+			// Reported by the other validations
+			return
+		}
+		cert, err := parseCertificate(send.Certificates[0])
+		if err != nil {
+			return
+		}
+		if !key.PublicKey.Equal(cert.PublicKey) {
+			sl.ReportError(send.Certificates, "Certificates", "Certificates", "send_certificate_mismatch", "")
+		}
+	}, SENDConfig{})
+
+	// Adhoc custom validator which validates the RDNSS addresses are the
+	// IPv6 addresses or "self". The invalid address is reported with the
+	// ipv6 tag in the same way as the other address fields.
+	validate.RegisterStructValidation(func(sl validator.StructLevel) {
+		rdnss := sl.Current().Interface().(RDNSSConfig)
+		for i, addr := range rdnss.Addresses {
+			if addr == rdnssSelf {
+				continue
+			}
+			if ip := net.ParseIP(addr); ip == nil || ip.To4() != nil {
+				field := fmt.Sprintf("Addresses[%d]", i)
+				sl.ReportError(addr, field, field, "ipv6", "")
+			}
+		}
+	}, RDNSSConfig{})
+
+	// Adhoc custom validator which validates the RDNSS addresses don't
+	// have "self"
+	validate.RegisterValidation("no_self_rdnss", func(fl validator.FieldLevel) bool {
+		rdnssSlice := fl.Field()
+		for i := 0; i < rdnssSlice.Len(); i++ {
+			if rdnssSlice.Index(i).IsNil() {
+				// Reported by the other validations
+				continue
+			}
+			rdnss := rdnssSlice.Index(i).Interface().(*RDNSSConfig)
+			if slices.Contains(rdnss.Addresses, rdnssSelf) {
+				return false
+			}
+		}
+		return true
+	})
+
+	// Adhoc custom validator which validates the field is only set for
+	// the auto mode prefix
+	validate.RegisterValidation("auto_prefix_only", func(fl validator.FieldLevel) bool {
+		return fl.Field().IsZero() || isAutoPrefix(fl.Parent().FieldByName("Prefix").String())
+	})
+
+	// Adhoc custom validator which validates the Prefix fields are not
+	// in the auto mode
+	validate.RegisterValidation("no_auto_prefix", func(fl validator.FieldLevel) bool {
+		prefixSlice := fl.Field()
+		for i := 0; i < prefixSlice.Len(); i++ {
+			if prefixSlice.Index(i).IsNil() {
+				// Reported by the other validations
+				continue
+			}
+			if isAutoPrefix(prefixSlice.Index(i).Elem().FieldByName("Prefix").String()) {
+				return false
+			}
+		}
+		return true
+	})
+
+	// Adhoc custom validator which validates the string is a well-known
+	// route protocol name or a protocol number
+	validate.RegisterValidation("route_protocol", func(fl validator.FieldLevel) bool {
+		_, ok := parseRouteProtocol(fl.Field().String())
+		return ok
+	})
+
+	// Adhoc custom validator which validates the probe target matches
+	// the probe type
+	validate.RegisterValidation("health_check_target", func(fl validator.FieldLevel) bool {
+		target := fl.Field().String()
+		if target == "" {
+			// Reported by the other validations
+			return true
+		}
+		switch fl.Parent().FieldByName("Type").String() {
+		case "tcp":
+			_, port, err := net.SplitHostPort(target)
+			if err != nil {
+				return false
+			}
+			n, err := strconv.Atoi(port)
+			return err == nil && n > 0 && n <= 65535
+		case "icmp":
+			addr, err := netip.ParseAddr(target)
+			return err == nil && addr.Is6() && !addr.Is4In6() && addr.Zone() == ""
+		}
+		return true
+	})
+
 	if err := validate.Struct(c); err != nil {
 		if _, ok := err.(*validator.InvalidValidationError); ok {
 			panic("BUG (Please report 🙏): Invalid validation: " + err.Error())
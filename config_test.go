@@ -5,7 +5,10 @@ package ra
 
 import (
 	"bytes"
+	"crypto/x509"
+	"encoding/pem"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/go-playground/validator/v10"
@@ -66,7 +69,45 @@ interfaces:
 
 }
 
+func TestConfigDerivedDefaults(t *testing.T) {
+	t.Run("Min/MaxRAIntervalMilliseconds are derived from RAIntervalMilliseconds", func(t *testing.T) {
+		c := &Config{
+			Interfaces: []*InterfaceConfig{
+				{
+					Name:                   "net0",
+					RAIntervalMilliseconds: 1000,
+				},
+			},
+		}
+		require.NoError(t, c.defaultAndValidate())
+		require.Equal(t, 1000, c.Interfaces[0].MaxRAIntervalMilliseconds)
+		require.Equal(t, 330, c.Interfaces[0].MinRAIntervalMilliseconds)
+	})
+
+	t.Run("MinRAIntervalMilliseconds is derived from MaxRAIntervalMilliseconds", func(t *testing.T) {
+		c := &Config{
+			Interfaces: []*InterfaceConfig{
+				{
+					Name:                      "net0",
+					RAIntervalMilliseconds:    1000,
+					MaxRAIntervalMilliseconds: 3000,
+				},
+			},
+		}
+		require.NoError(t, c.defaultAndValidate())
+		require.Equal(t, 3000, c.Interfaces[0].MaxRAIntervalMilliseconds)
+		require.Equal(t, 990, c.Interfaces[0].MinRAIntervalMilliseconds)
+	})
+}
+
 func TestConfigValidation(t *testing.T) {
+	sendKey, sendKeyPEM, sendCertPEM := newTestSENDCredentials(t)
+	_, otherKeyPEM, _ := newTestSENDCredentials(t)
+
+	pkcs8Key, err := x509.MarshalPKCS8PrivateKey(sendKey)
+	require.NoError(t, err)
+	sendPKCS8KeyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Key}))
+
 	tests := []struct {
 		name        string
 		config      *Config
@@ -153,501 +194,525 @@ func TestConfigValidation(t *testing.T) {
 			errorTag:    "lte",
 		},
 		{
-			name: "CurrentHopLimit < 0",
+			name: "MaxRAIntervalMilliseconds < 70",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						CurrentHopLimit:        -1,
+						Name:                      "net0",
+						RAIntervalMilliseconds:    1000,
+						MaxRAIntervalMilliseconds: 69,
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "CurrentHopLimit",
+			errorField:  "MaxRAIntervalMilliseconds",
 			errorTag:    "gte",
 		},
 		{
-			name: "CurrentHopLimit > 255",
+			name: "MaxRAIntervalMilliseconds > 1800000",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						CurrentHopLimit:        256,
+						Name:                      "net0",
+						RAIntervalMilliseconds:    1000,
+						MaxRAIntervalMilliseconds: 1800001,
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "CurrentHopLimit",
+			errorField:  "MaxRAIntervalMilliseconds",
 			errorTag:    "lte",
 		},
 		{
-			name: "RouterLifetimeSeconds < 0",
-			config: &Config{
-				Interfaces: []*InterfaceConfig{
-					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						RouterLifetimeSeconds:  -1,
-					},
-				},
-			},
-			expectError: true,
-			errorField:  "RouterLifetimeSeconds",
-			errorTag:    "gte",
-		},
-		{
-			name: "RouterLifetimeSeconds > 65535",
+			name: "MinRAIntervalMilliseconds = 0.75 * MaxRAIntervalMilliseconds",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						RouterLifetimeSeconds:  65536,
+						Name:                      "net0",
+						RAIntervalMilliseconds:    1000,
+						MinRAIntervalMilliseconds: 750,
+						MaxRAIntervalMilliseconds: 1000,
 					},
 				},
 			},
-			expectError: true,
-			errorField:  "RouterLifetimeSeconds",
-			errorTag:    "lte",
+			expectError: false,
 		},
 		{
-			name: "ReachableTimeMilliseconds < 0",
+			name: "MinRAIntervalMilliseconds > 0.75 * MaxRAIntervalMilliseconds",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                      "net0",
 						RAIntervalMilliseconds:    1000,
-						ReachableTimeMilliseconds: -1,
+						MinRAIntervalMilliseconds: 751,
+						MaxRAIntervalMilliseconds: 1000,
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "ReachableTimeMilliseconds",
-			errorTag:    "gte",
+			errorField:  "MinRAIntervalMilliseconds",
+			errorTag:    "lte_three_quarters MaxRAIntervalMilliseconds",
 		},
 		{
-			name: "ReachableTimeMilliseconds > 4294967295",
+			name: "MinRAIntervalMilliseconds > 0.75 * derived MaxRAIntervalMilliseconds",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                      "net0",
 						RAIntervalMilliseconds:    1000,
-						ReachableTimeMilliseconds: 4294967296,
+						MinRAIntervalMilliseconds: 751,
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "ReachableTimeMilliseconds",
-			errorTag:    "lte",
+			errorField:  "MinRAIntervalMilliseconds",
+			errorTag:    "lte_three_quarters MaxRAIntervalMilliseconds",
 		},
 		{
-			name: "RetransmitTimeMilliseconds < 0",
+			name: "InitialRACount = 0",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                       "net0",
-						RAIntervalMilliseconds:     1000,
-						RetransmitTimeMilliseconds: -1,
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						InitialRACount:         ptr.To(0),
 					},
 				},
 			},
-			expectError: true,
-			errorField:  "RetransmitTimeMilliseconds",
-			errorTag:    "gte",
+			expectError: false,
 		},
 		{
-			name: "RetransmitTimeMilliseconds > 4294967295",
+			name: "InitialRACount > 3",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                       "net0",
-						RAIntervalMilliseconds:     1000,
-						RetransmitTimeMilliseconds: 4294967296,
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						InitialRACount:         ptr.To(4),
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "RetransmitTimeMilliseconds",
+			errorField:  "InitialRACount",
 			errorTag:    "lte",
 		},
 		{
-			name: "MTU > 0",
+			name: "FinalRACount > 3",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						MTU:                    -1,
+						FinalRACount:           ptr.To(4),
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "MTU",
-			errorTag:    "gte",
+			errorField:  "FinalRACount",
+			errorTag:    "lte",
 		},
 		{
-			name: "MTU > 4294967295",
+			name: "Valid HomeAgent",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						MTU:                    4294967296,
+						Name:                     "net0",
+						RAIntervalMilliseconds:   1000,
+						HomeAgent:                true,
+						HomeAgentPreference:      -32768,
+						HomeAgentLifetimeSeconds: 65535,
 					},
 				},
 			},
-			expectError: true,
-			errorField:  "MTU",
-			errorTag:    "lte",
+			expectError: false,
 		},
-
-		// PrefixConfig
 		{
-			name: "Nil PrefixConfig",
+			name: "HomeAgent without HomeAgentLifetimeSeconds",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Prefixes:               nil,
+						HomeAgent:              true,
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "HomeAgentLifetimeSeconds",
+			errorTag:    "required_if",
 		},
 		{
-			name: "Empty PrefixConfig",
+			name: "HomeAgentLifetimeSeconds > 65535",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						Prefixes:               []*PrefixConfig{},
+						Name:                     "net0",
+						RAIntervalMilliseconds:   1000,
+						HomeAgent:                true,
+						HomeAgentLifetimeSeconds: 65536,
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "HomeAgentLifetimeSeconds",
+			errorTag:    "lte",
 		},
 		{
-			name: "Nil PrefixConfig Element",
+			name: "HomeAgentPreference < -32768",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						Prefixes:               []*PrefixConfig{nil},
+						Name:                     "net0",
+						RAIntervalMilliseconds:   1000,
+						HomeAgent:                true,
+						HomeAgentLifetimeSeconds: 1800,
+						HomeAgentPreference:      -32769,
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "Prefix",
-			errorTag:    "required",
+			errorField:  "HomeAgentPreference",
+			errorTag:    "gte",
 		},
 		{
-			name: "No Prefix",
+			name: "HomeAgentPreference > 32767",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						Prefixes: []*PrefixConfig{
-							{
-								OnLink: true,
-							},
-						},
+						Name:                     "net0",
+						RAIntervalMilliseconds:   1000,
+						HomeAgent:                true,
+						HomeAgentLifetimeSeconds: 1800,
+						HomeAgentPreference:      32768,
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "Prefix",
-			errorTag:    "required",
+			errorField:  "HomeAgentPreference",
+			errorTag:    "lte",
 		},
 		{
-			name: "Overlapping Prefix",
+			name: "Valid ExtensionFlags",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						Prefixes: []*PrefixConfig{
-							{
-								Prefix: "2001:db8::/32",
-							},
-							{
-								Prefix: "2001:db8::/64",
-							},
-						},
+						Name:                       "net0",
+						RAIntervalMilliseconds:     1000,
+						NDProxy:                    true,
+						ExtensionFlags:             0xffffffffffff,
+						ExperimentalExtensionFlags: true,
 					},
 				},
 			},
-			expectError: true,
-			errorField:  "Prefixes",
-			errorTag:    "non_overlapping_prefix",
+			expectError: false,
 		},
 		{
-			name: "ValidLifetimeSeconds = 4294967295",
+			name: "ExtensionFlags with reserved bits",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Prefixes: []*PrefixConfig{
-							{
-								Prefix:               "2001:db8::/64",
-								ValidLifetimeSeconds: ptr.To(4294967295),
-							},
-						},
+						NDProxy:                true,
+						ExtensionFlags:         0xffffffffffff,
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "ExtensionFlags",
+			errorTag:    "extension_flags",
 		},
 		{
-			name: "ValidLifetimeSeconds < 0",
+			name: "ExtensionFlags < 0",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Prefixes: []*PrefixConfig{
-							{
-								Prefix:               "2001:db8::/64",
-								ValidLifetimeSeconds: ptr.To(-1),
-							},
-						},
+						ExtensionFlags:         -1,
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "ValidLifetimeSeconds",
+			errorField:  "ExtensionFlags",
 			errorTag:    "gte",
 		},
 		{
-			name: "ValidLifetimeSeconds > 4294967295",
+			name: "ExtensionFlags with bits beyond 48 bits",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Prefixes: []*PrefixConfig{
-							{
-								Prefix:               "2001:db8::/64",
-								ValidLifetimeSeconds: ptr.To(4294967296),
-							},
-						},
+						ExtensionFlags:         0x1000000000000,
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "ValidLifetimeSeconds",
+			errorField:  "ExtensionFlags",
 			errorTag:    "lte",
 		},
 		{
-			name: "PreferredLifetimeSeconds = 4294967295",
+			name: "MinDelayBetweenRAsMilliseconds > 1800000",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						Prefixes: []*PrefixConfig{
-							{
-								Prefix:                   "2001:db8::/64",
-								ValidLifetimeSeconds:     ptr.To(4294967295), // PreferredLifetimeSeconds must be less than ValidLifetimeSeconds
-								PreferredLifetimeSeconds: ptr.To(4294967295),
-							},
-						},
+						Name:                           "net0",
+						RAIntervalMilliseconds:         1000,
+						MinDelayBetweenRAsMilliseconds: ptr.To(1800001),
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "MinDelayBetweenRAsMilliseconds",
+			errorTag:    "lte",
 		},
 		{
-			name: "PreferredLifetimeSeconds < 0",
+			name: "RSRateLimitPerSecond < 0",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Prefixes: []*PrefixConfig{
-							{
-								Prefix:                   "2001:db8::/64",
-								PreferredLifetimeSeconds: ptr.To(-1),
-							},
-						},
+						RSRateLimitPerSecond:   ptr.To(-1),
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "PreferredLifetimeSeconds",
+			errorField:  "RSRateLimitPerSecond",
 			errorTag:    "gte",
 		},
 		{
-			name: "PreferredLifetimeSeconds > 4294967295",
+			name: "RSRateLimitBurst < 1",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Prefixes: []*PrefixConfig{
-							{
-								Prefix:                   "2001:db8::/64",
-								ValidLifetimeSeconds:     ptr.To(4294967296),
-								PreferredLifetimeSeconds: ptr.To(4294967296),
-							},
-						},
+						RSRateLimitBurst:       -1,
 					},
 				},
 			},
 			expectError: true,
-			// PreferredLifetimeSeconds must be less than
-			// ValidLifetimeSeconds, but ValdateLifetimeSeconds
-			// must be <= 4294967295, so it's impossible to specify
-			// PreferredLifetimeSeconds > 4294967295 actually.
-			errorField: "ValidLifetimeSeconds",
-			errorTag:   "lte",
+			errorField:  "RSRateLimitBurst",
+			errorTag:    "gte",
 		},
 		{
-			name: "ValidLifetimeSeconds < PreferredLifetimeSeconds",
+			name: "InitialRAIntervalMilliseconds < 70",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						Prefixes: []*PrefixConfig{
-							{
-								Prefix:                   "2001:db8::/64",
-								ValidLifetimeSeconds:     ptr.To(100),
-								PreferredLifetimeSeconds: ptr.To(101),
-							},
-						},
+						Name:                          "net0",
+						RAIntervalMilliseconds:        1000,
+						InitialRAIntervalMilliseconds: 69,
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "PreferredLifetimeSeconds",
-			errorTag:    "ltefield",
+			errorField:  "InitialRAIntervalMilliseconds",
+			errorTag:    "gte",
 		},
 		{
-			name: "Preference low && RouterLifetimeSeconds != 0",
+			name: "InitialRAIntervalMilliseconds > 16000",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
-						RAIntervalMilliseconds: 1000,
-						Preference:             "low",
-						RouterLifetimeSeconds:  1,
+						Name:                          "net0",
+						RAIntervalMilliseconds:        1000,
+						InitialRAIntervalMilliseconds: 16001,
 					},
 				},
 			},
+			expectError: true,
+			errorField:  "InitialRAIntervalMilliseconds",
+			errorTag:    "lte",
 		},
 		{
-			name: "Preference medium && RouterLifetimeSeconds != 0",
+			name: "CurrentHopLimit < 0",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Preference:             "medium",
-						RouterLifetimeSeconds:  1,
+						CurrentHopLimit:        -1,
 					},
 				},
 			},
+			expectError: true,
+			errorField:  "CurrentHopLimit",
+			errorTag:    "gte",
 		},
 		{
-			name: "Preference high && RouterLifetimeSeconds != 0",
+			name: "CurrentHopLimit > 255",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Preference:             "high",
-						RouterLifetimeSeconds:  1,
+						CurrentHopLimit:        256,
 					},
 				},
 			},
+			expectError: true,
+			errorField:  "CurrentHopLimit",
+			errorTag:    "lte",
 		},
 		{
-			name: "Preference foo && RouterLifetimeSeconds != 0",
+			name: "RouterLifetimeSeconds < 0",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Preference:             "foo",
-						RouterLifetimeSeconds:  1,
+						RouterLifetimeSeconds:  -1,
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "Preference",
-			errorTag:    "oneof",
+			errorField:  "RouterLifetimeSeconds",
+			errorTag:    "gte",
 		},
 		{
-			name: "Preference == low && RouterLifetimeSeconds == 0",
+			name: "RouterLifetimeSeconds > 65535",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Preference:             "low",
-						RouterLifetimeSeconds:  0,
+						RouterLifetimeSeconds:  65536,
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "Preference",
-			errorTag:    "eq_if medium RouterLifetimeSeconds 0",
+			errorField:  "RouterLifetimeSeconds",
+			errorTag:    "lte",
 		},
 		{
-			name: "Preference == <empty> && RouterLifetimeSeconds == 0",
+			name: "ReachableTimeMilliseconds < 0",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
+						Name:                      "net0",
+						RAIntervalMilliseconds:    1000,
+						ReachableTimeMilliseconds: -1,
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "ReachableTimeMilliseconds",
+			errorTag:    "gte",
+		},
+		{
+			name: "ReachableTimeMilliseconds > 4294967295",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                      "net0",
+						RAIntervalMilliseconds:    1000,
+						ReachableTimeMilliseconds: 4294967296,
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "ReachableTimeMilliseconds",
+			errorTag:    "lte",
+		},
+		{
+			name: "RetransmitTimeMilliseconds < 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                       "net0",
+						RAIntervalMilliseconds:     1000,
+						RetransmitTimeMilliseconds: -1,
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "RetransmitTimeMilliseconds",
+			errorTag:    "gte",
+		},
+		{
+			name: "RetransmitTimeMilliseconds > 4294967295",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                       "net0",
+						RAIntervalMilliseconds:     1000,
+						RetransmitTimeMilliseconds: 4294967296,
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "RetransmitTimeMilliseconds",
+			errorTag:    "lte",
+		},
+		{
+			name: "MTU > 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						RouterLifetimeSeconds:  0,
+						MTU:                    -1,
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "MTU",
+			errorTag:    "gte",
+		},
+		{
+			name: "MTU > 4294967295",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						MTU:                    4294967296,
 					},
 				},
 			},
+			expectError: true,
+			errorField:  "MTU",
+			errorTag:    "lte",
 		},
 
-		// RouteConfig
+		// PrefixConfig
 		{
-			name: "Nil RouteConfig",
+			name: "Nil PrefixConfig",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Routes:                 nil,
+						Prefixes:               nil,
 					},
 				},
 			},
 			expectError: false,
 		},
 		{
-			name: "Empty RouteConfig",
+			name: "Empty PrefixConfig",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Routes:                 []*RouteConfig{},
+						Prefixes:               []*PrefixConfig{},
 					},
 				},
 			},
 			expectError: false,
 		},
 		{
-			name: "Nil RouteConfig Element",
+			name: "Nil PrefixConfig Element",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Routes:                 []*RouteConfig{nil},
+						Prefixes:               []*PrefixConfig{nil},
 					},
 				},
 			},
@@ -662,8 +727,10 @@ func TestConfigValidation(t *testing.T) {
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Routes: []*RouteConfig{
-							{},
+						Prefixes: []*PrefixConfig{
+							{
+								OnLink: true,
+							},
 						},
 					},
 				},
@@ -673,13 +740,16 @@ func TestConfigValidation(t *testing.T) {
 			errorTag:    "required",
 		},
 		{
-			name: "No LifetimeSeconds",
+			name: "Overlapping Prefix",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Routes: []*RouteConfig{
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix: "2001:db8::/32",
+							},
 							{
 								Prefix: "2001:db8::/64",
 							},
@@ -688,302 +758,2761 @@ func TestConfigValidation(t *testing.T) {
 				},
 			},
 			expectError: true,
-			errorField:  "LifetimeSeconds",
-			errorTag:    "required",
+			errorField:  "Prefixes",
+			errorTag:    "non_overlapping_prefix",
 		},
 		{
-			name: "Duplicated Prefix",
+			name: "DHCPv6PDPreferred Prefix",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						Routes: []*RouteConfig{
+						Prefixes: []*PrefixConfig{
 							{
-								Prefix:          "2001:db8::/64",
-								LifetimeSeconds: 100,
+								Prefix:            "2001:db8::/64",
+								DHCPv6PDPreferred: true,
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "DHCPv6PDPreferred Link-Local Prefix",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:            "fe80::/64",
+								DHCPv6PDPreferred: true,
 							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "DHCPv6PDPreferred",
+			errorTag:    "dhcpv6_pd_preferred",
+		},
+		{
+			name: "Link-Local Prefix without DHCPv6PDPreferred",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Prefixes: []*PrefixConfig{
 							{
-								Prefix:          "2001:db8::/64",
-								LifetimeSeconds: 100,
+								Prefix: "fe80::/64",
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "ValidLifetimeSeconds = 4294967295",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:               "2001:db8::/64",
+								ValidLifetimeSeconds: ptr.To(4294967295),
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "ValidLifetimeSeconds < 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:               "2001:db8::/64",
+								ValidLifetimeSeconds: ptr.To(-1),
 							},
 						},
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "Routes",
-			errorTag:    "unique",
+			errorField:  "ValidLifetimeSeconds",
+			errorTag:    "gte",
 		},
-
-		// RDNSSConfig
 		{
-			name: "Valid RDNSSConfig",
+			name: "ValidLifetimeSeconds > 4294967295",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:               "2001:db8::/64",
+								ValidLifetimeSeconds: ptr.To(4294967296),
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "ValidLifetimeSeconds",
+			errorTag:    "lte",
+		},
+		{
+			name: "PreferredLifetimeSeconds = 4294967295",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:                   "2001:db8::/64",
+								ValidLifetimeSeconds:     ptr.To(4294967295), // PreferredLifetimeSeconds must be less than ValidLifetimeSeconds
+								PreferredLifetimeSeconds: ptr.To(4294967295),
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "PreferredLifetimeSeconds < 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:                   "2001:db8::/64",
+								PreferredLifetimeSeconds: ptr.To(-1),
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "PreferredLifetimeSeconds",
+			errorTag:    "gte",
+		},
+		{
+			name: "PreferredLifetimeSeconds > 4294967295",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:                   "2001:db8::/64",
+								ValidLifetimeSeconds:     ptr.To(4294967296),
+								PreferredLifetimeSeconds: ptr.To(4294967296),
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			// PreferredLifetimeSeconds must be less than
+			// ValidLifetimeSeconds, but ValdateLifetimeSeconds
+			// must be <= 4294967295, so it's impossible to specify
+			// PreferredLifetimeSeconds > 4294967295 actually.
+			errorField: "ValidLifetimeSeconds",
+			errorTag:   "lte",
+		},
+		{
+			name: "ValidLifetimeSeconds < PreferredLifetimeSeconds",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:                   "2001:db8::/64",
+								ValidLifetimeSeconds:     ptr.To(100),
+								PreferredLifetimeSeconds: ptr.To(101),
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "PreferredLifetimeSeconds",
+			errorTag:    "ltefield",
+		},
+		{
+			name: "Preference low && RouterLifetimeSeconds != 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Preference:             "low",
+						RouterLifetimeSeconds:  1,
+					},
+				},
+			},
+		},
+		{
+			name: "Preference medium && RouterLifetimeSeconds != 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Preference:             "medium",
+						RouterLifetimeSeconds:  1,
+					},
+				},
+			},
+		},
+		{
+			name: "Preference high && RouterLifetimeSeconds != 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Preference:             "high",
+						RouterLifetimeSeconds:  1,
+					},
+				},
+			},
+		},
+		{
+			name: "Preference foo && RouterLifetimeSeconds != 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Preference:             "foo",
+						RouterLifetimeSeconds:  1,
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Preference",
+			errorTag:    "oneof",
+		},
+		{
+			name: "Preference == low && RouterLifetimeSeconds == 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Preference:             "low",
+						RouterLifetimeSeconds:  0,
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Preference",
+			errorTag:    "eq_if medium RouterLifetimeSeconds 0",
+		},
+		{
+			name: "Preference == <empty> && RouterLifetimeSeconds == 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RouterLifetimeSeconds:  0,
+					},
+				},
+			},
+		},
+
+		// RouteConfig
+		{
+			name: "Nil RouteConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Routes:                 nil,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Empty RouteConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Routes:                 []*RouteConfig{},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Nil RouteConfig Element",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Routes:                 []*RouteConfig{nil},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Prefix",
+			errorTag:    "required",
+		},
+		{
+			name: "No Prefix",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Routes: []*RouteConfig{
+							{},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Prefix",
+			errorTag:    "required",
+		},
+		{
+			name: "No LifetimeSeconds",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Routes: []*RouteConfig{
+							{
+								Prefix: "2001:db8::/64",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "LifetimeSeconds",
+			errorTag:    "required",
+		},
+		{
+			name: "Duplicated Prefix",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Routes: []*RouteConfig{
+							{
+								Prefix:          "2001:db8::/64",
+								LifetimeSeconds: 100,
+							},
+							{
+								Prefix:          "2001:db8::/64",
+								LifetimeSeconds: 100,
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Routes",
+			errorTag:    "unique",
+		},
+
+		// RDNSSConfig
+		{
+			name: "Valid RDNSSConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RDNSSes: []*RDNSSConfig{
+							{
+								LifetimeSeconds: 100,
+								Addresses: []string{
+									"fd00::1",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Multiple RDNSSConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RDNSSes: []*RDNSSConfig{
+							{
+								LifetimeSeconds: 100,
+								Addresses: []string{
+									"fd00::1",
+								},
+							},
+							{
+								LifetimeSeconds: 100,
+								Addresses: []string{
+									"fd00::2",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Nil RDNSSConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RDNSSes:                nil,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Empty RDNSSConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RDNSSes:                []*RDNSSConfig{},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Nil RDNSSConfig Element",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RDNSSes:                []*RDNSSConfig{nil},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "LifetimeSeconds",
+			errorTag:    "required",
+		},
+		{
+			name: "No Addresses",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RDNSSes: []*RDNSSConfig{
+							{
+								LifetimeSeconds: 100,
+								Addresses:       []string{},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Addresses",
+			errorTag:    "min",
+		},
+		{
+			name: "Duplicated Addresses",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RDNSSes: []*RDNSSConfig{
+							{
+								LifetimeSeconds: 100,
+								Addresses: []string{
+									"fd00::1",
+									"fd00::1",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Addresses",
+			errorTag:    "unique",
+		},
+		{
+			name: "Invalid Address",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RDNSSes: []*RDNSSConfig{
+							{
+								LifetimeSeconds: 100,
+								Addresses: []string{
+									"10.0.0.1",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Addresses[0]",
+			errorTag:    "ipv6",
+		},
+
+		// DNSSLConfig
+		{
+			name: "Valid DNSSLConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNSSLs: []*DNSSLConfig{
+							{
+								LifetimeSeconds: 100,
+								DomainNames: []string{
+									"example.com",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Multiple DNSSLConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNSSLs: []*DNSSLConfig{
+							{
+								LifetimeSeconds: 100,
+								DomainNames: []string{
+									"example.com",
+									"foo.example.com",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Nil DNSSLConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNSSLs:                 nil,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Empty DNSSLConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNSSLs:                 []*DNSSLConfig{},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Nil DNSSLConfig Element",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNSSLs:                 []*DNSSLConfig{nil},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "LifetimeSeconds",
+			errorTag:    "required",
+		},
+		{
+			name: "No DomainNames",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNSSLs: []*DNSSLConfig{
+							{
+								LifetimeSeconds: 100,
+								DomainNames:     []string{},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "DomainNames",
+			errorTag:    "min",
+		},
+		{
+			name: "Duplicated DomainNames",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNSSLs: []*DNSSLConfig{
+							{
+								LifetimeSeconds: 100,
+								DomainNames: []string{
+									"example.com",
+									"example.com",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "DomainNames",
+			errorTag:    "unique",
+		},
+		{
+			name: "Qualified DomainName",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNSSLs: []*DNSSLConfig{
+							{
+								LifetimeSeconds: 100,
+								DomainNames: []string{
+									// Shouldn't be qualified.
+									"example.com.",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "DomainNames[0]",
+			errorTag:    "domain",
+		},
+		{
+			name: "IP address DomainName",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNSSLs: []*DNSSLConfig{
+							{
+								LifetimeSeconds: 100,
+								DomainNames: []string{
+									"10.0.0.0",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "DomainNames[0]",
+			errorTag:    "domain",
+		},
+
+		// NAT64PrefixConfig
+		{
+			name: "Nil NAT64PrefixConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						NAT64Prefixes:          nil,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Empty NAT64PrefixConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						NAT64Prefixes:          []*NAT64PrefixConfig{},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Nil NAT64PrefixConfig Element",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						NAT64Prefixes:          []*NAT64PrefixConfig{nil},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Prefix",
+			errorTag:    "required",
+		},
+		{
+			name: "No NAT64Prefix",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						NAT64Prefixes: []*NAT64PrefixConfig{
+							{
+								LifetimeSeconds: ptr.To(1800),
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Prefix",
+			errorTag:    "required",
+		},
+		{
+			name: "Multiple NAT64PrefixConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						NAT64Prefixes: []*NAT64PrefixConfig{
+							{
+								Prefix:          "fc64:ff9b::/96",
+								LifetimeSeconds: ptr.To(1800),
+							},
+							{
+								Prefix:          "fd64:ff9b::/96",
+								LifetimeSeconds: ptr.To(1800),
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid NAT64Prefix length",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						NAT64Prefixes: []*NAT64PrefixConfig{
+							{
+								Prefix: "64:ff9b::/104",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Prefix",
+			errorTag:    "invalid_prefix_len",
+		},
+		{
+			name: "LifetimeSeconds = 65528",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						NAT64Prefixes: []*NAT64PrefixConfig{
+							{
+								Prefix:          "64:ff9b::/96",
+								LifetimeSeconds: ptr.To(65528),
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "LifetimeSeconds < 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						NAT64Prefixes: []*NAT64PrefixConfig{
+							{
+								Prefix:          "64:ff9b::/96",
+								LifetimeSeconds: ptr.To(-1),
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "LifetimeSeconds",
+			errorTag:    "gte",
+		},
+		{
+			name: "LifetimeSeconds > 65528",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						NAT64Prefixes: []*NAT64PrefixConfig{
+							{
+								Prefix:          "64:ff9b::/96",
+								LifetimeSeconds: ptr.To(65529),
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "LifetimeSeconds",
+			errorTag:    "lte",
+		},
+		// CaptivePortal
+		{
+			name: "Valid CaptivePortal",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						CaptivePortal:          "https://portal.example.com/api",
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Unrestricted CaptivePortal",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						CaptivePortal:          "urn:ietf:params:capport:unrestricted",
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "CaptivePortal with http scheme",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						CaptivePortal:          "http://portal.example.com/api",
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "CaptivePortal",
+			errorTag:    "captive_portal",
+		},
+		{
+			name: "CaptivePortal with IPv4 literal",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						CaptivePortal:          "https://192.0.2.1/api",
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "CaptivePortal",
+			errorTag:    "captive_portal",
+		},
+		{
+			name: "CaptivePortal with IPv6 literal",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						CaptivePortal:          "https://[2001:db8::1]/api",
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "CaptivePortal",
+			errorTag:    "captive_portal",
+		},
+		{
+			name: "CaptivePortal with IP literal in path",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						CaptivePortal:          "https://portal.example.com/2001:db8::1",
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "CaptivePortal",
+			errorTag:    "captive_portal",
+		},
+		{
+			name: "Relative CaptivePortal",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						CaptivePortal:          "/api",
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "CaptivePortal",
+			errorTag:    "captive_portal",
+		},
+		{
+			name: "CaptivePortal > 246 bytes",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						CaptivePortal:          "https://portal.example.com/" + strings.Repeat("a", 220),
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "CaptivePortal",
+			errorTag:    "max",
+		},
+		// DNRConfig
+		{
+			name: "Valid DNRConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs: []*DNRConfig{
+							{
+								ServicePriority:          1,
+								LifetimeSeconds:          600,
+								AuthenticationDomainName: "dns.example.com",
+								Addresses:                []string{"2001:db8::53"},
+								ALPNs:                    []string{"dot"},
+								Port:                     853,
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Nil DNRConfig Element",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs:                   []*DNRConfig{nil},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "AuthenticationDomainName",
+			errorTag:    "required",
+		},
+		{
+			name: "DNR ServicePriority = 0",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs: []*DNRConfig{
+							{
+								LifetimeSeconds:          600,
+								AuthenticationDomainName: "dns.example.com",
+								Addresses:                []string{"2001:db8::53"},
+								ALPNs:                    []string{"dot"},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "ServicePriority",
+			errorTag:    "required",
+		},
+		{
+			name: "DNR ServicePriority > 65535",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs: []*DNRConfig{
+							{
+								ServicePriority:          65536,
+								LifetimeSeconds:          600,
+								AuthenticationDomainName: "dns.example.com",
+								Addresses:                []string{"2001:db8::53"},
+								ALPNs:                    []string{"dot"},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "ServicePriority",
+			errorTag:    "lte",
+		},
+		{
+			name: "No DNR AuthenticationDomainName",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs: []*DNRConfig{
+							{
+								ServicePriority: 1,
+								LifetimeSeconds: 600,
+								Addresses:       []string{"2001:db8::53"},
+								ALPNs:           []string{"dot"},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "AuthenticationDomainName",
+			errorTag:    "required",
+		},
+		{
+			name: "Invalid DNR AuthenticationDomainName",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs: []*DNRConfig{
+							{
+								ServicePriority:          1,
+								LifetimeSeconds:          600,
+								AuthenticationDomainName: "-invalid.example.com",
+								Addresses:                []string{"2001:db8::53"},
+								ALPNs:                    []string{"dot"},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "AuthenticationDomainName",
+			errorTag:    "domain",
+		},
+		{
+			name: "No DNR Addresses",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs: []*DNRConfig{
+							{
+								ServicePriority:          1,
+								LifetimeSeconds:          600,
+								AuthenticationDomainName: "dns.example.com",
+								ALPNs:                    []string{"dot"},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Addresses",
+			errorTag:    "required",
+		},
+		{
+			name: "Invalid DNR Address",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs: []*DNRConfig{
+							{
+								ServicePriority:          1,
+								LifetimeSeconds:          600,
+								AuthenticationDomainName: "dns.example.com",
+								Addresses:                []string{"192.0.2.1"},
+								ALPNs:                    []string{"dot"},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Addresses[0]",
+			errorTag:    "ipv6",
+		},
+		{
+			name: "No DNR ALPNs",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs: []*DNRConfig{
+							{
+								ServicePriority:          1,
+								LifetimeSeconds:          600,
+								AuthenticationDomainName: "dns.example.com",
+								Addresses:                []string{"2001:db8::53"},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "ALPNs",
+			errorTag:    "required",
+		},
+		{
+			name: "Empty DNR ALPN",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs: []*DNRConfig{
+							{
+								ServicePriority:          1,
+								LifetimeSeconds:          600,
+								AuthenticationDomainName: "dns.example.com",
+								Addresses:                []string{"2001:db8::53"},
+								ALPNs:                    []string{""},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "ALPNs[0]",
+			errorTag:    "min",
+		},
+		{
+			name: "DNR Port > 65535",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs: []*DNRConfig{
+							{
+								ServicePriority:          1,
+								LifetimeSeconds:          600,
+								AuthenticationDomainName: "dns.example.com",
+								Addresses:                []string{"2001:db8::53"},
+								ALPNs:                    []string{"dot"},
+								Port:                     65536,
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Port",
+			errorTag:    "lte",
+		},
+		{
+			name: "Too long DNR option",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						DNRs: []*DNRConfig{
+							{
+								ServicePriority:          1,
+								LifetimeSeconds:          600,
+								AuthenticationDomainName: "dns.example.com",
+								Addresses:                []string{"2001:db8::1", "2001:db8::2", "2001:db8::3", "2001:db8::4", "2001:db8::5", "2001:db8::6", "2001:db8::7", "2001:db8::8", "2001:db8::9", "2001:db8::10", "2001:db8::11", "2001:db8::12", "2001:db8::13", "2001:db8::14"},
+								ALPNs:                    []string{"dot"},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "DNRConfig",
+			errorTag:    "dnr_option_too_long",
+		},
+		// PvDConfig
+		{
+			name: "Valid PvDConfig",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PvDs: []*PvDConfig{
+							{
+								FQDN:           "pvd.example.com",
+								HTTP:           true,
+								Delay:          15,
+								SequenceNumber: 65535,
+								Prefixes: []*PrefixConfig{
+									{
+										Prefix: "fd00::/64",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "No PvD FQDN",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PvDs: []*PvDConfig{
+							{},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "FQDN",
+			errorTag:    "required",
+		},
+		{
+			name: "Invalid PvD FQDN",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PvDs: []*PvDConfig{
+							{
+								FQDN: "-pvd.example.com",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "FQDN",
+			errorTag:    "domain",
+		},
+		{
+			name: "Duplicated PvD FQDN",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PvDs: []*PvDConfig{
+							{
+								FQDN: "pvd.example.com",
+							},
+							{
+								FQDN: "pvd.example.com",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "PvDs",
+			errorTag:    "unique",
+		},
+		{
+			name: "PvD Delay > 15",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PvDs: []*PvDConfig{
+							{
+								FQDN:  "pvd.example.com",
+								Delay: 16,
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Delay",
+			errorTag:    "lte",
+		},
+		{
+			name: "PvD SequenceNumber > 65535",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PvDs: []*PvDConfig{
+							{
+								FQDN:           "pvd.example.com",
+								SequenceNumber: 65536,
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "SequenceNumber",
+			errorTag:    "lte",
+		},
+		{
+			name: "Invalid PvD Prefix",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PvDs: []*PvDConfig{
+							{
+								FQDN: "pvd.example.com",
+								Prefixes: []*PrefixConfig{
+									{
+										Prefix: "192.0.2.0/24",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Prefix",
+			errorTag:    "cidrv6",
+		},
+		{
+			name: "Overlapping PvD Prefixes",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PvDs: []*PvDConfig{
+							{
+								FQDN: "pvd.example.com",
+								Prefixes: []*PrefixConfig{
+									{
+										Prefix: "fd00::/64",
+									},
+									{
+										Prefix: "fd00::/48",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Prefixes",
+			errorTag:    "non_overlapping_prefix",
+		},
+		{
+			name: "Too long PvD option",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PvDs: []*PvDConfig{
+							{
+								FQDN: "pvd.example.com",
+								Prefixes: []*PrefixConfig{
+									{
+										Prefix: "fd00:1::/64",
+									},
+									{
+										Prefix: "fd00:2::/64",
+									},
+									{
+										Prefix: "fd00:3::/64",
+									},
+									{
+										Prefix: "fd00:4::/64",
+									},
+									{
+										Prefix: "fd00:5::/64",
+									},
+									{
+										Prefix: "fd00:6::/64",
+									},
+									{
+										Prefix: "fd00:7::/64",
+									},
+									{
+										Prefix: "fd00:8::/64",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "PvDConfig",
+			errorTag:    "pvd_option_too_long",
+		},
+		{
+			name: "Valid SEND",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   sendKeyPEM,
+							Certificates: []string{sendCertPEM},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid SEND with PKCS #8 Key and Sec = 1",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   sendPKCS8KeyPEM,
+							Certificates: []string{sendCertPEM},
+							Sec:          1,
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid SEND Modifier",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   sendKeyPEM,
+							Certificates: []string{sendCertPEM},
+							Modifier:     "00112233445566778899aabbccddeeff",
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Short SEND Modifier",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   sendKeyPEM,
+							Certificates: []string{sendCertPEM},
+							Modifier:     "0011223344556677",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Modifier",
+			errorTag:    "cga_modifier",
+		},
+		{
+			name: "Non-hex SEND Modifier",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   sendKeyPEM,
+							Certificates: []string{sendCertPEM},
+							Modifier:     "0x112233445566778899aabbccddeeff",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Modifier",
+			errorTag:    "cga_modifier",
+		},
+		{
+			name: "Nil SEND PrivateKey",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							Certificates: []string{sendCertPEM},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "PrivateKey",
+			errorTag:    "required",
+		},
+		{
+			name: "Invalid SEND PrivateKey",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   "foo",
+							Certificates: []string{sendCertPEM},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "PrivateKey",
+			errorTag:    "rsa_private_key",
+		},
+		{
+			name: "SEND PrivateKey with Certificate",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   sendCertPEM,
+							Certificates: []string{sendCertPEM},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "PrivateKey",
+			errorTag:    "rsa_private_key",
+		},
+		{
+			name: "Nil SEND Certificates",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey: sendKeyPEM,
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Certificates",
+			errorTag:    "required",
+		},
+		{
+			name: "Empty SEND Certificates",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   sendKeyPEM,
+							Certificates: []string{},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Certificates",
+			errorTag:    "min",
+		},
+		{
+			name: "Invalid SEND Certificate",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   sendKeyPEM,
+							Certificates: []string{sendCertPEM, "foo"},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Certificates[1]",
+			errorTag:    "x509_certificate",
+		},
+		{
+			name: "SEND Certificate for Other Key",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   otherKeyPEM,
+							Certificates: []string{sendCertPEM},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Certificates",
+			errorTag:    "send_certificate_mismatch",
+		},
+		{
+			name: "SEND Sec > 1",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   sendKeyPEM,
+							Certificates: []string{sendCertPEM},
+							Sec:          2,
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Sec",
+			errorTag:    "lte",
+		},
+		{
+			name: "Valid RawOptions",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Type: 34,
+								Hex:  "40100000003c20010db800000000",
+							},
+							{
+								Type:   253,
+								Base64: "AQIDBAUG",
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Nil RawOptions Element",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							nil,
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Type",
+			errorTag:    "required",
+		},
+		{
+			name: "RawOption without Type",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Hex: "010203040506",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Type",
+			errorTag:    "required",
+		},
+		{
+			name: "RawOption Type > 255",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Type: 256,
+								Hex:  "010203040506",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Type",
+			errorTag:    "lte",
+		},
+		{
+			name: "RawOption with Native Type",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Type: 25,
+								Hex:  "010203040506",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Type",
+			errorTag:    "raw_option_type",
+		},
+		{
+			name: "RawOption with Target Link-Layer Address Type",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Type: 2,
+								Hex:  "112233445566",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Type",
+			errorTag:    "raw_option_type",
+		},
+		{
+			name: "RawOption without Payload",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Type: 253,
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Hex",
+			errorTag:    "required_without",
+		},
+		{
+			name: "RawOption with Both Hex and Base64",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Type:   253,
+								Hex:    "010203040506",
+								Base64: "AQIDBAUG",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Hex",
+			errorTag:    "excluded_with",
+		},
+		{
+			name: "RawOption with Invalid Hex",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Type: 253,
+								Hex:  "0x0102030405",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Hex",
+			errorTag:    "raw_option_encoding",
+		},
+		{
+			name: "RawOption with Invalid Base64",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Type:   253,
+								Base64: "AQIDBAU",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Base64",
+			errorTag:    "raw_option_encoding",
+		},
+		{
+			name: "RawOption not Aligned to 8 Bytes",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Type: 253,
+								Hex:  "0102030405",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Hex",
+			errorTag:    "raw_option_alignment",
+		},
+		{
+			name: "Empty RawOption Payload",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Type:   253,
+								Base64: "",
+								Hex:    "",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Hex",
+			errorTag:    "required_without",
+		},
+		{
+			name: "RawOption Too Long",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RawOptions: []*RawOptionConfig{
+							{
+								Type: 253,
+								Hex:  strings.Repeat("00", 254),
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Hex",
+			errorTag:    "raw_option_too_long",
+		},
+		{
+			name: "Valid UnicastOnly",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						UnicastOnly:            true,
+						Clients:                []string{"fe80::1", "fe80::2"},
+						ClientsOnly:            true,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "UnicastOnly without Clients",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						UnicastOnly:            true,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Non-Link-Local Client",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Clients:                []string{"2001:db8::1"},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Clients[0]",
+			errorTag:    "link_local_unicast",
+		},
+		{
+			name: "Non-IPv6 Client",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Clients:                []string{"192.168.0.1"},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Clients[0]",
+			errorTag:    "ipv6",
+		},
+		{
+			name: "Duplicated Clients",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						Clients:                []string{"fe80::1", "fe80::1"},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Clients",
+			errorTag:    "unique",
+		},
+		{
+			name: "ClientsOnly without Clients",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						ClientsOnly:            true,
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "ClientsOnly",
+			errorTag:    "clients_only",
+		},
+		{
+			name: "Valid Low-Power Profile",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PowerProfile:           "low-power",
+						RouterLifetimeSeconds:  65535,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix: "2001:db8::/64",
+							},
+							{
+								Prefix:                   "2001:db8:1::/64",
+								ValidLifetimeSeconds:     ptr.To(0),
+								PreferredLifetimeSeconds: ptr.To(0),
+							},
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid PowerProfile",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PowerProfile:           "high-power",
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "PowerProfile",
+			errorTag:    "oneof",
+		},
+		{
+			name: "LowPowerMulticastRAIntervalSeconds < 600",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                               "net0",
+						RAIntervalMilliseconds:             1000,
+						PowerProfile:                       "low-power",
+						LowPowerMulticastRAIntervalSeconds: 599,
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "LowPowerMulticastRAIntervalSeconds",
+			errorTag:    "gte",
+		},
+		{
+			name: "Too Short RouterLifetimeSeconds for Low-Power Profile",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PowerProfile:           "low-power",
+						RouterLifetimeSeconds:  1800,
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "RouterLifetimeSeconds",
+			errorTag:    "low_power_lifetime",
+		},
+		{
+			name: "Too Short RouterLifetimeSeconds for Default Profile",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						RouterLifetimeSeconds:  1800,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Too Short ValidLifetimeSeconds for Low-Power Profile",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                               "net0",
+						RAIntervalMilliseconds:             1000,
+						PowerProfile:                       "low-power",
+						LowPowerMulticastRAIntervalSeconds: 3600,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:                   "2001:db8::/64",
+								ValidLifetimeSeconds:     ptr.To(7200),
+								PreferredLifetimeSeconds: ptr.To(3600),
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Prefixes[0].ValidLifetimeSeconds",
+			errorTag:    "low_power_lifetime",
+		},
+		{
+			name: "Too Short PreferredLifetimeSeconds of PvD for Low-Power Profile",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						PowerProfile:           "low-power",
+						PvDs: []*PvDConfig{
+							{
+								FQDN: "pvd.example.com",
+								Prefixes: []*PrefixConfig{
+									{
+										Prefix:                   "2001:db8::/64",
+										PreferredLifetimeSeconds: ptr.To(3600),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "PvDs[0].Prefixes[0].PreferredLifetimeSeconds",
+			errorTag:    "low_power_lifetime",
+		},
+		{
+			name: "Valid VirtualRouter",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						VirtualRouter: &VirtualRouterConfig{
+							MACAddress:       "00:00:5e:00:02:01",
+							LinkLocalAddress: "fe80::1",
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "VirtualRouter without MACAddress",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						VirtualRouter: &VirtualRouterConfig{
+							LinkLocalAddress: "fe80::1",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "MACAddress",
+			errorTag:    "required",
+		},
+		{
+			name: "VirtualRouter with Invalid MACAddress",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						VirtualRouter: &VirtualRouterConfig{
+							MACAddress:       "00:00:5e:00:02",
+							LinkLocalAddress: "fe80::1",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "MACAddress",
+			errorTag:    "mac",
+		},
+		{
+			name: "VirtualRouter with Non-Link-Local Address",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						VirtualRouter: &VirtualRouterConfig{
+							MACAddress:       "00:00:5e:00:02:01",
+							LinkLocalAddress: "2001:db8::1",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "LinkLocalAddress",
+			errorTag:    "link_local_unicast",
+		},
+		{
+			name: "VirtualRouter with SEND",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						SEND: &SENDConfig{
+							PrivateKey:   sendKeyPEM,
+							Certificates: []string{sendCertPEM},
+						},
+						VirtualRouter: &VirtualRouterConfig{
+							MACAddress:       "00:00:5e:00:02:01",
+							LinkLocalAddress: "fe80::1",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "VirtualRouter",
+			errorTag:    "excluded_with",
+		},
+		{
+			name: "Valid TrackRoute",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						TrackRoute: &TrackRouteConfig{
+							Prefix: "::/0",
+							Table:  100,
+							Action: "low-preference",
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "TrackRoute with IPv4 Prefix",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						TrackRoute: &TrackRouteConfig{
+							Prefix: "0.0.0.0/0",
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "TrackRoute without Prefix",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						TrackRoute:             &TrackRouteConfig{},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Prefix",
+			errorTag:    "required",
+		},
+		{
+			name: "TrackRoute with Invalid Prefix",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						TrackRoute: &TrackRouteConfig{
+							Prefix: "2001:db8::1",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Prefix",
+			errorTag:    "cidr",
+		},
+		{
+			name: "TrackRoute with Invalid Action",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						TrackRoute: &TrackRouteConfig{
+							Prefix: "::/0",
+							Action: "foo",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Action",
+			errorTag:    "oneof",
+		},
+		{
+			name: "Valid TCP HealthCheck",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						HealthCheck: &HealthCheckConfig{
+							Type:   "tcp",
+							Target: "[2001:db8::1]:80",
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid ICMP HealthCheck",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						HealthCheck: &HealthCheckConfig{
+							Type:   "icmp",
+							Target: "fe80::1",
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid Exec HealthCheck",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						HealthCheck: &HealthCheckConfig{
+							Type:    "exec",
+							Command: []string{"/bin/true"},
+							Rise:    1,
+							Fall:    1,
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "HealthCheck with Invalid Type",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						HealthCheck: &HealthCheckConfig{
+							Type:   "http",
+							Target: "[2001:db8::1]:80",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Type",
+			errorTag:    "oneof",
+		},
+		{
+			name: "TCP HealthCheck without Target",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						HealthCheck: &HealthCheckConfig{
+							Type: "tcp",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Target",
+			errorTag:    "required_unless",
+		},
+		{
+			name: "TCP HealthCheck without Port",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						HealthCheck: &HealthCheckConfig{
+							Type:   "tcp",
+							Target: "2001:db8::1",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Target",
+			errorTag:    "health_check_target",
+		},
+		{
+			name: "ICMP HealthCheck with IPv4 Target",
+			config: &Config{
+				Interfaces: []*InterfaceConfig{
+					{
+						Name:                   "net0",
+						RAIntervalMilliseconds: 1000,
+						HealthCheck: &HealthCheckConfig{
+							Type:   "icmp",
+							Target: "192.0.2.1",
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorField:  "Target",
+			errorTag:    "health_check_target",
+		},
+		{
+			name: "Exec HealthCheck without Command",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						RDNSSes: []*RDNSSConfig{
-							{
-								LifetimeSeconds: 100,
-								Addresses: []string{
-									"fd00::1",
-								},
-							},
+						HealthCheck: &HealthCheckConfig{
+							Type: "exec",
 						},
 					},
 				},
 			},
+			expectError: true,
+			errorField:  "Command",
+			errorTag:    "required_if",
 		},
 		{
-			name: "Multiple RDNSSConfig",
+			name: "Exec HealthCheck with Target",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						RDNSSes: []*RDNSSConfig{
-							{
-								LifetimeSeconds: 100,
-								Addresses: []string{
-									"fd00::1",
-								},
-							},
-							{
-								LifetimeSeconds: 100,
-								Addresses: []string{
-									"fd00::2",
-								},
-							},
+						HealthCheck: &HealthCheckConfig{
+							Type:    "exec",
+							Target:  "[2001:db8::1]:80",
+							Command: []string{"/bin/true"},
 						},
 					},
 				},
 			},
+			expectError: true,
+			errorField:  "Target",
+			errorTag:    "excluded_if",
 		},
 		{
-			name: "Nil RDNSSConfig",
+			name: "TCP HealthCheck with Command",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						RDNSSes:                nil,
+						HealthCheck: &HealthCheckConfig{
+							Type:    "tcp",
+							Target:  "[2001:db8::1]:80",
+							Command: []string{"/bin/true"},
+						},
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "Command",
+			errorTag:    "excluded_unless",
 		},
 		{
-			name: "Empty RDNSSConfig",
+			name: "HealthCheck with Timeout Longer than Interval",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						RDNSSes:                []*RDNSSConfig{},
+						HealthCheck: &HealthCheckConfig{
+							Type:                 "tcp",
+							Target:               "[2001:db8::1]:80",
+							IntervalMilliseconds: 1000,
+							TimeoutMilliseconds:  2000,
+						},
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "TimeoutMilliseconds",
+			errorTag:    "ltefield",
 		},
 		{
-			name: "Nil RDNSSConfig Element",
+			name: "Valid RouteSource",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						RDNSSes:                []*RDNSSConfig{nil},
+						RouteSource: &RouteSourceConfig{
+							Table:      100,
+							Protocols:  []string{"bgp", "186"},
+							Prefixes:   []string{"2001:db8::/32"},
+							Preference: "high",
+						},
 					},
 				},
 			},
-			expectError: true,
-			errorField:  "LifetimeSeconds",
-			errorTag:    "required",
+			expectError: false,
 		},
 		{
-			name: "No Addresses",
+			name: "RouteSource with Invalid Protocol",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						RDNSSes: []*RDNSSConfig{
-							{
-								LifetimeSeconds: 100,
-								Addresses:       []string{},
-							},
+						RouteSource: &RouteSourceConfig{
+							Protocols: []string{"foo"},
 						},
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "Addresses",
-			errorTag:    "min",
+			errorField:  "Protocols[0]",
+			errorTag:    "route_protocol",
 		},
 		{
-			name: "Duplicated Addresses",
+			name: "RouteSource with Too Large Protocol Number",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						RDNSSes: []*RDNSSConfig{
-							{
-								LifetimeSeconds: 100,
-								Addresses: []string{
-									"fd00::1",
-									"fd00::1",
-								},
-							},
+						RouteSource: &RouteSourceConfig{
+							Protocols: []string{"256"},
 						},
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "Addresses",
-			errorTag:    "unique",
+			errorField:  "Protocols[0]",
+			errorTag:    "route_protocol",
 		},
 		{
-			name: "Invalid Address",
+			name: "RouteSource with Duplicated Protocols",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						RDNSSes: []*RDNSSConfig{
-							{
-								LifetimeSeconds: 100,
-								Addresses: []string{
-									"10.0.0.1",
-								},
-							},
+						RouteSource: &RouteSourceConfig{
+							Protocols: []string{"bgp", "bgp"},
 						},
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "Addresses[0]",
-			errorTag:    "ipv6",
+			errorField:  "Protocols",
+			errorTag:    "unique",
 		},
-
-		// DNSSLConfig
 		{
-			name: "Valid DNSSLConfig",
+			name: "RouteSource with IPv4 Prefix",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						DNSSLs: []*DNSSLConfig{
-							{
-								LifetimeSeconds: 100,
-								DomainNames: []string{
-									"example.com",
-								},
-							},
+						RouteSource: &RouteSourceConfig{
+							Prefixes: []string{"10.0.0.0/8"},
 						},
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "Prefixes[0]",
+			errorTag:    "cidrv6",
 		},
 		{
-			name: "Multiple DNSSLConfig",
+			name: "RouteSource with Invalid Preference",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						DNSSLs: []*DNSSLConfig{
-							{
-								LifetimeSeconds: 100,
-								DomainNames: []string{
-									"example.com",
-									"foo.example.com",
-								},
-							},
+						RouteSource: &RouteSourceConfig{
+							Preference: "foo",
 						},
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "Preference",
+			errorTag:    "oneof",
 		},
 		{
-			name: "Nil DNSSLConfig",
+			name: "Valid Auto Prefix",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						DNSSLs:                 nil,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:              "::/64",
+								AutoScope:           "global",
+								UseAddressLifetimes: true,
+							},
+							{
+								Prefix: "2001:db8::/64",
+							},
+						},
 					},
 				},
 			},
 			expectError: false,
 		},
 		{
-			name: "Empty DNSSLConfig",
+			name: "UseAddressLifetimes without Auto Prefix",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						DNSSLs:                 []*DNSSLConfig{},
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:              "2001:db8::/64",
+								UseAddressLifetimes: true,
+							},
+						},
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "UseAddressLifetimes",
+			errorTag:    "auto_prefix_only",
 		},
 		{
-			name: "Nil DNSSLConfig Element",
+			name: "Invalid AutoScope",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						DNSSLs:                 []*DNSSLConfig{nil},
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:    "::/64",
+								AutoScope: "site",
+							},
+						},
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "LifetimeSeconds",
-			errorTag:    "required",
+			errorField:  "AutoScope",
+			errorTag:    "oneof",
 		},
 		{
-			name: "No DomainNames",
+			name: "Overlapping Auto Prefixes",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						DNSSLs: []*DNSSLConfig{
+						Prefixes: []*PrefixConfig{
 							{
-								LifetimeSeconds: 100,
-								DomainNames:     []string{},
+								Prefix: "::/64",
+							},
+							{
+								Prefix: "::/56",
 							},
 						},
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "DomainNames",
-			errorTag:    "min",
+			errorField:  "Prefixes",
+			errorTag:    "non_overlapping_prefix",
 		},
 		{
-			name: "Duplicated DomainNames",
+			name: "Auto Prefix in PvD",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						DNSSLs: []*DNSSLConfig{
+						PvDs: []*PvDConfig{
 							{
-								LifetimeSeconds: 100,
-								DomainNames: []string{
-									"example.com",
-									"example.com",
+								FQDN: "pvd.example.com",
+								Prefixes: []*PrefixConfig{
+									{
+										Prefix: "::/64",
+									},
 								},
 							},
 						},
@@ -991,214 +3520,222 @@ func TestConfigValidation(t *testing.T) {
 				},
 			},
 			expectError: true,
-			errorField:  "DomainNames",
-			errorTag:    "unique",
+			errorField:  "Prefixes",
+			errorTag:    "no_auto_prefix",
 		},
 		{
-			name: "Qualified DomainName",
+			name: "Valid Upstream Prefix",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						DNSSLs: []*DNSSLConfig{
+						Prefixes: []*PrefixConfig{
 							{
-								LifetimeSeconds: 100,
-								DomainNames: []string{
-									// Shouldn't be qualified.
-									"example.com.",
-								},
+								Prefix:            "::/64",
+								UpstreamInterface: "wan0",
+								SubnetID:          1,
 							},
 						},
 					},
 				},
 			},
-			expectError: true,
-			errorField:  "DomainNames[0]",
-			errorTag:    "domain",
+			expectError: false,
 		},
 		{
-			name: "IP address DomainName",
+			name: "UpstreamInterface without Auto Prefix",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						DNSSLs: []*DNSSLConfig{
+						Prefixes: []*PrefixConfig{
 							{
-								LifetimeSeconds: 100,
-								DomainNames: []string{
-									"10.0.0.0",
-								},
+								Prefix:            "2001:db8::/64",
+								UpstreamInterface: "wan0",
 							},
 						},
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "DomainNames[0]",
-			errorTag:    "domain",
+			errorField:  "UpstreamInterface",
+			errorTag:    "auto_prefix_only",
 		},
-
-		// NAT64PrefixConfig
 		{
-			name: "Nil NAT64PrefixConfig",
+			name: "SubnetID without UpstreamInterface",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						NAT64Prefixes:          nil,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:   "::/64",
+								SubnetID: 1,
+							},
+						},
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "SubnetID",
+			errorTag:    "excluded_without",
 		},
 		{
-			name: "Empty NAT64PrefixConfig",
+			name: "Negative SubnetID",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						NAT64Prefixes:          []*NAT64PrefixConfig{},
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:            "::/64",
+								UpstreamInterface: "wan0",
+								SubnetID:          -1,
+							},
+						},
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "SubnetID",
+			errorTag:    "gte",
 		},
 		{
-			name: "Nil NAT64PrefixConfig Element",
+			name: "Self RDNSS Address",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						NAT64Prefixes:          []*NAT64PrefixConfig{nil},
+						RDNSSes: []*RDNSSConfig{
+							{
+								LifetimeSeconds: 100,
+								Addresses: []string{
+									"self",
+									"fd00::1",
+								},
+							},
+						},
 					},
 				},
 			},
-			expectError: true,
-			errorField:  "Prefix",
-			errorTag:    "required",
 		},
 		{
-			name: "No NAT64Prefix",
+			name: "Duplicated Self RDNSS Address",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						NAT64Prefixes: []*NAT64PrefixConfig{
+						RDNSSes: []*RDNSSConfig{
 							{
-								LifetimeSeconds: ptr.To(1800),
+								LifetimeSeconds: 100,
+								Addresses: []string{
+									"self",
+									"self",
+								},
 							},
 						},
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "Prefix",
-			errorTag:    "required",
+			errorField:  "Addresses",
+			errorTag:    "unique",
 		},
 		{
-			name: "Multiple NAT64PrefixConfig",
+			name: "Invalid Address with Self RDNSS Address",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						NAT64Prefixes: []*NAT64PrefixConfig{
-							{
-								Prefix:          "fc64:ff9b::/96",
-								LifetimeSeconds: ptr.To(1800),
-							},
+						RDNSSes: []*RDNSSConfig{
 							{
-								Prefix:          "fd64:ff9b::/96",
-								LifetimeSeconds: ptr.To(1800),
+								LifetimeSeconds: 100,
+								Addresses: []string{
+									"self",
+									"myself",
+								},
 							},
 						},
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorField:  "Addresses[1]",
+			errorTag:    "ipv6",
 		},
 		{
-			name: "Invalid NAT64Prefix length",
+			name: "Self RDNSS Address in PvD",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
 						Name:                   "net0",
 						RAIntervalMilliseconds: 1000,
-						NAT64Prefixes: []*NAT64PrefixConfig{
+						PvDs: []*PvDConfig{
 							{
-								Prefix: "64:ff9b::/104",
+								FQDN: "pvd.example.com",
+								RDNSSes: []*RDNSSConfig{
+									{
+										LifetimeSeconds: 100,
+										Addresses:       []string{"self"},
+									},
+								},
 							},
 						},
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "Prefix",
-			errorTag:    "invalid_prefix_len",
+			errorField:  "RDNSSes",
+			errorTag:    "no_self_rdnss",
 		},
 		{
-			name: "LifetimeSeconds = 65528",
+			name: "Valid LinkLocalAddress",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
+						Name:                   "wg0",
 						RAIntervalMilliseconds: 1000,
-						NAT64Prefixes: []*NAT64PrefixConfig{
-							{
-								Prefix:          "64:ff9b::/96",
-								LifetimeSeconds: ptr.To(65528),
-							},
-						},
+						LinkLocalAddress:       "fe80::1",
 					},
 				},
 			},
 			expectError: false,
 		},
 		{
-			name: "LifetimeSeconds < 0",
+			name: "Non-Link-Local LinkLocalAddress",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
+						Name:                   "wg0",
 						RAIntervalMilliseconds: 1000,
-						NAT64Prefixes: []*NAT64PrefixConfig{
-							{
-								Prefix:          "64:ff9b::/96",
-								LifetimeSeconds: ptr.To(-1),
-							},
-						},
+						LinkLocalAddress:       "2001:db8::1",
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "LifetimeSeconds",
-			errorTag:    "gte",
+			errorField:  "LinkLocalAddress",
+			errorTag:    "link_local_unicast",
 		},
 		{
-			name: "LifetimeSeconds > 65528",
+			name: "Invalid LinkLocalAddress",
 			config: &Config{
 				Interfaces: []*InterfaceConfig{
 					{
-						Name:                   "net0",
+						Name:                   "wg0",
 						RAIntervalMilliseconds: 1000,
-						NAT64Prefixes: []*NAT64PrefixConfig{
-							{
-								Prefix:          "64:ff9b::/96",
-								LifetimeSeconds: ptr.To(65529),
-							},
-						},
+						LinkLocalAddress:       "fe80::xyz",
 					},
 				},
 			},
 			expectError: true,
-			errorField:  "LifetimeSeconds",
-			errorTag:    "lte",
+			errorField:  "LinkLocalAddress",
+			errorTag:    "ipv6",
 		},
 	}
 
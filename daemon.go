@@ -18,9 +18,13 @@ type Daemon struct {
 	logger            *slog.Logger
 	socketConstructor socketCtor
 	deviceWatcher     deviceWatcher
+	routeWatcher      routeWatcher
 
 	advertisers     map[string]*advertiser
 	advertisersLock sync.RWMutex
+
+	// Tracks the running advertisers including the stopping ones
+	advertisersWg sync.WaitGroup
 }
 
 // NewDaemon creates a new Daemon instance with the provided configuration and
@@ -41,6 +45,7 @@ func NewDaemon(config *Config, opts ...DaemonOption) (*Daemon, error) {
 		logger:            slog.Default(),
 		socketConstructor: newSocket,
 		deviceWatcher:     newDeviceWatcher(),
+		routeWatcher:      newRouteWatcher(),
 		advertisers:       map[string]*advertiser{},
 	}
 
@@ -51,7 +56,9 @@ func NewDaemon(config *Config, opts ...DaemonOption) (*Daemon, error) {
 	return d, nil
 }
 
-// Run starts the daemon and blocks until the context is cancelled
+// Run starts the daemon and blocks until the context is cancelled and all
+// advertisers stop. Stopping advertisers may send the final RAs, but it is
+// bounded by a short deadline.
 func (d *Daemon) Run(ctx context.Context) {
 	d.logger.Info("Starting daemon")
 
@@ -91,8 +98,12 @@ reload:
 		// Add new per-interface jobs
 		for _, c := range toAdd {
 			d.logger.Info("Adding new RA sender", slog.String("interface", c.Name))
-			advertiser := newAdvertiser(c, d.socketConstructor, d.deviceWatcher, d.logger)
-			go advertiser.run(ctx)
+			advertiser := newAdvertiser(c, d.socketConstructor, d.deviceWatcher, d.routeWatcher, d.logger)
+			d.advertisersWg.Add(1)
+			go func() {
+				defer d.advertisersWg.Done()
+				advertiser.run(ctx)
+			}()
 			d.advertisers[c.Name] = advertiser
 		}
 
@@ -125,6 +136,7 @@ reload:
 				continue reload
 			case <-ctx.Done():
 				d.logger.Info("Shutting down daemon")
+				d.advertisersWg.Wait()
 				return
 			}
 		}
@@ -198,3 +210,11 @@ func withDeviceWatcher(w deviceWatcher) DaemonOption {
 		d.deviceWatcher = w
 	}
 }
+
+// withRouteWatcher overrides the default route watcher with the provided
+// one. For testing purposes only.
+func withRouteWatcher(w routeWatcher) DaemonOption {
+	return func(d *Daemon) {
+		d.routeWatcher = w
+	}
+}
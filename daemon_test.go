@@ -4,19 +4,35 @@
 package ra
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
 	"net"
 	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/mdlayher/ndp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 	"k8s.io/utils/ptr"
 )
 
-func assertRAInterval(ct *assert.CollectT, sock *fakeSock, interval time.Duration) bool {
+func assertRAInterval(ct *assert.CollectT, sock *fakeSock, minInterval, maxInterval time.Duration) bool {
 	// wait until we get 3 RAs
 	timeout, cancel := context.WithTimeout(context.Background(), time.Second*1)
 
@@ -36,12 +52,36 @@ outer:
 		}
 	}
 
-	// Ensure the interval is correct. We let 60ms of error margin.
-	mergin := float64(60 * time.Millisecond)
+	// Ensure the interval is within the range. We let 60ms of error margin.
+	mergin := 60 * time.Millisecond
 	diff0 := ras[1].tstamp.Sub(ras[0].tstamp)
 	diff1 := ras[2].tstamp.Sub(ras[1].tstamp)
 
-	return assert.InDelta(ct, interval, diff0, mergin) && assert.InDelta(ct, interval, diff1, mergin)
+	return assertIntervalInRange(ct, diff0, minInterval-mergin, maxInterval+mergin) &&
+		assertIntervalInRange(ct, diff1, minInterval-mergin, maxInterval+mergin)
+}
+
+func assertIntervalInRange(t assert.TestingT, interval, minInterval, maxInterval time.Duration) bool {
+	return assert.GreaterOrEqual(t, interval, minInterval) && assert.LessOrEqual(t, interval, maxInterval)
+}
+
+// raSourceLinkLayerAddress returns the addrLength-byte address in the Source
+// Link-Layer Address option of the RA. Returns nil if the option doesn't
+// exist.
+func raSourceLinkLayerAddress(msg *ndp.RouterAdvertisement, addrLength int) net.HardwareAddr {
+	for _, option := range msg.Options {
+		switch opt := option.(type) {
+		case *ndp.LinkLayerAddress:
+			if opt.Direction == ndp.Source {
+				return opt.Addr
+			}
+		case *ndp.RawOption:
+			if opt.Type == byte(ndp.Source) && len(opt.Value) >= addrLength {
+				return net.HardwareAddr(opt.Value[:addrLength])
+			}
+		}
+	}
+	return nil
 }
 
 func TestDaemonHappyPath(t *testing.T) {
@@ -58,6 +98,14 @@ func TestDaemonHappyPath(t *testing.T) {
 				ReachableTimeMilliseconds:  10000,
 				RetransmitTimeMilliseconds: 10000,
 				MTU:                        1500,
+				HomeAgent:                  true,
+				HomeAgentPreference:        -1,
+				HomeAgentLifetimeSeconds:   1000,
+				NDProxy:                    true,
+				ExtensionFlags:             0x800000000001,
+				ExperimentalExtensionFlags: true,
+				AdvertisementInterval:      true,
+				CaptivePortal:              "https://portal.example.com/api",
 				Prefixes: []*PrefixConfig{
 					{
 						Prefix:                   "fd00::/64",
@@ -97,6 +145,26 @@ func TestDaemonHappyPath(t *testing.T) {
 						LifetimeSeconds: ptr.To(1800),
 					},
 				},
+				DNRs: []*DNRConfig{
+					{
+						ServicePriority:          1,
+						LifetimeSeconds:          600,
+						AuthenticationDomainName: "dns.example.com",
+						Addresses:                []string{"2001:db8::53"},
+						ALPNs:                    []string{"dot"},
+						Port:                     853,
+					},
+				},
+				RawOptions: []*RawOptionConfig{
+					{
+						Type: 34,
+						Hex:  "40100000003c20010db800000000",
+					},
+					{
+						Type:   253,
+						Base64: "AQIDBAUG",
+					},
+				},
 			},
 			{
 				Name:                   "net1",
@@ -142,13 +210,13 @@ func TestDaemonHappyPath(t *testing.T) {
 		sock, err = reg.getSock("net0")
 		require.NoError(t, err)
 		require.EventuallyWithT(t, func(ct *assert.CollectT) {
-			assertRAInterval(ct, sock, time.Millisecond*100)
+			assertRAInterval(ct, sock, time.Millisecond*33, time.Millisecond*100)
 		}, time.Second*1, time.Millisecond*100)
 
 		sock, err = reg.getSock("net1")
 		require.NoError(t, err)
 		require.EventuallyWithT(t, func(ct *assert.CollectT) {
-			assertRAInterval(ct, sock, time.Millisecond*100)
+			assertRAInterval(ct, sock, time.Millisecond*33, time.Millisecond*100)
 		}, time.Second*1, time.Millisecond*100)
 	})
 
@@ -169,6 +237,19 @@ func TestDaemonHappyPath(t *testing.T) {
 		require.Equal(t, time.Second*10, ra.msg.RouterLifetime)
 		require.Equal(t, time.Millisecond*10000, ra.msg.ReachableTime)
 		require.Equal(t, time.Millisecond*10000, ra.msg.RetransmitTimer)
+		require.True(t, ra.msg.MobileIPv6HomeAgent)
+		require.True(t, ra.msg.NeighborDiscoveryProxy)
+
+		// Find and check Flags Expansion option
+		var flagsOption *ndp.RAFlagsExtension
+		for _, option := range ra.msg.Options {
+			if opt, ok := option.(*ndp.RAFlagsExtension); ok {
+				flagsOption = opt
+				break
+			}
+		}
+		require.NotNil(t, flagsOption, "Flags Expansion option is not advertised")
+		require.Equal(t, ndp.RAFlags{0x80, 0x00, 0x00, 0x00, 0x00, 0x01}, flagsOption.Flags)
 
 		// Find MTU option
 		var mtuOption *ndp.MTU
@@ -182,15 +263,9 @@ func TestDaemonHappyPath(t *testing.T) {
 		require.Equal(t, uint32(1500), mtuOption.MTU, "Invalid MTU")
 
 		// Find and check Source Link-Layer Address option
-		var slaOption *ndp.LinkLayerAddress
-		for _, option := range ra.msg.Options {
-			if opt, ok := option.(*ndp.LinkLayerAddress); ok {
-				slaOption = opt
-				break
-			}
-		}
-		require.NotNil(t, slaOption, "Source Link-Layer Address option is not advertised")
-		require.Equal(t, net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77}, slaOption.Addr)
+		slaAddr := raSourceLinkLayerAddress(ra.msg, 7)
+		require.NotNil(t, slaAddr, "Source Link-Layer Address option is not advertised")
+		require.Equal(t, net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77}, slaAddr)
 
 		// Find and check Prefix Information options
 		prefixOptions := map[netip.Addr]*ndp.PrefixInformation{}
@@ -264,6 +339,100 @@ func TestDaemonHappyPath(t *testing.T) {
 		nat64prefixInfo := nat64prefixOptions[nat64prefix]
 		require.Equal(t, int(96), nat64prefixInfo.Prefix.Bits())
 		require.Equal(t, time.Second*1800, nat64prefixInfo.Lifetime)
+
+		// Find and check Captive-Portal option
+		var captivePortalOption *ndp.CaptivePortal
+		for _, option := range ra.msg.Options {
+			if opt, ok := option.(*ndp.CaptivePortal); ok {
+				captivePortalOption = opt
+				break
+			}
+		}
+		require.NotNil(t, captivePortalOption, "Captive-Portal option is not advertised")
+		require.Equal(t, "https://portal.example.com/api", captivePortalOption.URI)
+
+		// Find and check Advertisement Interval and Home Agent
+		// Information options
+		rawOptions := map[uint8]*ndp.RawOption{}
+		for _, option := range ra.msg.Options {
+			if opt, ok := option.(*ndp.RawOption); ok {
+				rawOptions[opt.Type] = opt
+			}
+		}
+		require.Contains(t, rawOptions, uint8(7), "Advertisement Interval option is not advertised")
+		require.Equal(t, uint8(1), rawOptions[7].Length)
+		require.Equal(t, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x64}, rawOptions[7].Value)
+		require.Contains(t, rawOptions, uint8(8), "Home Agent Information option is not advertised")
+		require.Equal(t, uint8(1), rawOptions[8].Length)
+		require.Equal(t, []byte{0x00, 0x00, 0xff, 0xff, 0x03, 0xe8}, rawOptions[8].Value)
+		require.Contains(t, rawOptions, uint8(34), "Raw option in hex is not advertised")
+		require.Equal(t, uint8(2), rawOptions[34].Length)
+		require.Equal(t, []byte{0x40, 0x10, 0x00, 0x00, 0x00, 0x3c, 0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00}, rawOptions[34].Value)
+		require.Contains(t, rawOptions, uint8(253), "Raw option in base64 is not advertised")
+		require.Equal(t, uint8(1), rawOptions[253].Length)
+		require.Equal(t, []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, rawOptions[253].Value)
+
+		// Find and check Encrypted DNS options
+		var dnrOption *ndp.RawOption
+		for _, option := range ra.msg.Options {
+			if opt, ok := option.(*ndp.RawOption); ok && opt.Type == 144 {
+				dnrOption = opt
+				break
+			}
+		}
+		require.NotNil(t, dnrOption, "Encrypted DNS option is not advertised")
+		require.Equal(t, uint8(8), dnrOption.Length)
+		require.Equal(t, []byte{
+			// Service Priority
+			0x00, 0x01,
+			// Lifetime
+			0x00, 0x00, 0x02, 0x58,
+			// ADN Length
+			0x00, 0x11,
+			// ADN
+			0x03, 'd', 'n', 's',
+			0x07, 'e', 'x', 'a', 'm', 'p', 'l', 'e',
+			0x03, 'c', 'o', 'm',
+			0x00,
+			// Addr Length
+			0x00, 0x10,
+			// IPv6 Address
+			0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x53,
+			// SvcParams Length
+			0x00, 0x0e,
+			// alpn=dot
+			0x00, 0x01, 0x00, 0x04, 0x03, 'd', 'o', 't',
+			// port=853
+			0x00, 0x03, 0x00, 0x02, 0x03, 0x55,
+			// Padding
+			0x00, 0x00, 0x00,
+		}, dnrOption.Value)
+	})
+
+	t.Run("Ensure the options survive the round trip", func(t *testing.T) {
+		sock, err := reg.getSock("net0")
+		require.NoError(t, err)
+
+		ra := <-sock.txMulticastCh()
+		msg := *ra.msg
+
+		// mdlayher/ndp can't parse the SLLA of the 56-bit address.
+		// Its encoding is checked in TestDaemonNonEthernetLinks.
+		msg.Options = slices.DeleteFunc(slices.Clone(msg.Options), isSourceLinkLayerAddressOption)
+
+		b, err := ndp.MarshalMessage(&msg)
+		require.NoError(t, err)
+
+		// All options must be padded to the multiple of 8 bytes
+		require.Zero(t, len(b)%8)
+
+		// M, O, H, Prf (high), and P flags in the RA header
+		require.Equal(t, byte(0x80|0x40|0x20|0x08|0x04), b[5])
+
+		parsed, err := ndp.ParseMessage(b)
+		require.NoError(t, err)
+		require.Equal(t, &msg, parsed)
 	})
 
 	t.Run("Ensure the status is running and the result is ordered by name", func(t *testing.T) {
@@ -274,6 +443,8 @@ func TestDaemonHappyPath(t *testing.T) {
 		assert.Equal(t, "net1", status.Interfaces[1].Name)
 		assert.Equal(t, Running, status.Interfaces[0].State)
 		assert.Equal(t, Running, status.Interfaces[1].State)
+		assert.NotZero(t, status.Interfaces[0].NextUnsolicitedRA)
+		assert.NotZero(t, status.Interfaces[1].NextUnsolicitedRA)
 	})
 
 	t.Run("Ensure Source Link Layer Address option is updated after device MAC address change", func(t *testing.T) {
@@ -288,19 +459,13 @@ func TestDaemonHappyPath(t *testing.T) {
 			ra := <-sock.txMulticastCh()
 
 			// Find and check Source Link-Layer Address option
-			var slaOption *ndp.LinkLayerAddress
-			for _, option := range ra.msg.Options {
-				if opt, ok := option.(*ndp.LinkLayerAddress); ok {
-					slaOption = opt
-					break
-				}
-			}
+			slaAddr := raSourceLinkLayerAddress(ra.msg, 7)
 
-			if !assert.NotNil(ct, slaOption, "Source Link-Layer Address option is not advertised") {
+			if !assert.NotNil(ct, slaAddr, "Source Link-Layer Address option is not advertised") {
 				return
 			}
 
-			assert.Equal(ct, net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x78}, slaOption.Addr)
+			assert.Equal(ct, net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x78}, slaAddr)
 		}, time.Second*1, time.Millisecond*100)
 	})
 
@@ -323,8 +488,8 @@ func TestDaemonHappyPath(t *testing.T) {
 			if !assert.NoError(t, err) {
 				return
 			}
-			assertRAInterval(ct, sock0, time.Millisecond*100)
-			assertRAInterval(ct, sock1, time.Millisecond*200)
+			assertRAInterval(ct, sock0, time.Millisecond*33, time.Millisecond*100)
+			assertRAInterval(ct, sock1, time.Millisecond*66, time.Millisecond*200)
 		}, time.Second*1, time.Millisecond*100)
 	})
 
@@ -337,7 +502,7 @@ func TestDaemonHappyPath(t *testing.T) {
 		rs := &ndp.RouterSolicitation{}
 
 		// Send RS
-		sock.rxCh() <- fakeRS{msg: rs, from: from}
+		sock.rxCh() <- fakeRS{msg: rs, from: from, hopLimit: 255}
 
 		// Wait for solicited RA
 		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
@@ -369,7 +534,7 @@ func TestDaemonHappyPath(t *testing.T) {
 			if !assert.NoError(t, err) {
 				return
 			}
-			assertRAInterval(ct, sock0, time.Millisecond*100)
+			assertRAInterval(ct, sock0, time.Millisecond*33, time.Millisecond*100)
 			assert.True(ct, sock1.isClosed())
 		}, time.Second*1, time.Millisecond*100)
 	})
@@ -393,3 +558,3172 @@ func TestDaemonHappyPath(t *testing.T) {
 		}, time.Second*1, time.Millisecond*100)
 	})
 }
+
+func receiveRAs(t *testing.T, sock *fakeSock, n int, timeout time.Duration) []fakeRA {
+	t.Helper()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	ras := []fakeRA{}
+	for len(ras) < n {
+		select {
+		case <-timer.C:
+			require.Failf(t, "timeout waiting for RAs", "got %d RAs out of %d", len(ras), n)
+		case ra := <-sock.txMulticastCh():
+			ras = append(ras, ra)
+		}
+	}
+
+	return ras
+}
+
+func TestDaemonInitialRAs(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "net0",
+				RAIntervalMilliseconds:         1000,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				MinRAIntervalMilliseconds:      750,
+				InitialRAIntervalMilliseconds:  100,
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// Ensure the first RA is sent immediately, the next two RAs are sent
+	// with the initial interval, and then the regular interval follows.
+	assertInitialRAs := func(t *testing.T, sock *fakeSock, since time.Time) {
+		ras := receiveRAs(t, sock, 4, time.Second*3)
+		mergin := float64(60 * time.Millisecond)
+		require.InDelta(t, 0, ras[0].tstamp.Sub(since), mergin)
+		require.InDelta(t, 100*time.Millisecond, ras[1].tstamp.Sub(ras[0].tstamp), mergin)
+		require.InDelta(t, 100*time.Millisecond, ras[2].tstamp.Sub(ras[1].tstamp), mergin)
+		assertIntervalInRange(t, ras[3].tstamp.Sub(ras[2].tstamp), 750*time.Millisecond-60*time.Millisecond, 1000*time.Millisecond+60*time.Millisecond)
+	}
+
+	getSock := func(t *testing.T) *fakeSock {
+		var sock *fakeSock
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			s, err := reg.getSock("net0")
+			if !assert.NoError(ct, err) {
+				return
+			}
+			if !assert.False(ct, s.isClosed()) {
+				return
+			}
+			sock = s
+		}, time.Second*1, time.Millisecond*10)
+		return sock
+	}
+
+	t.Run("Ensure initial RAs are sent after start", func(t *testing.T) {
+		since := time.Now()
+		go d.Run(ctx)
+		assertInitialRAs(t, getSock(t), since)
+	})
+
+	t.Run("Ensure initial RAs are sent after reload", func(t *testing.T) {
+		config.Interfaces[0].CurrentHopLimit = 10
+
+		since := time.Now()
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		err := d.Reload(timeout, config)
+		require.NoError(t, err)
+		cancelTimeout()
+
+		assertInitialRAs(t, getSock(t), since)
+	})
+
+	t.Run("Ensure initial RAs are sent after link up", func(t *testing.T) {
+		sock := getSock(t)
+
+		devWatcher.update("net0", deviceState{isUp: false, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+		require.Eventually(t, sock.isClosed, time.Second*1, time.Millisecond*10)
+
+		since := time.Now()
+		devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+		assertInitialRAs(t, getSock(t), since)
+	})
+}
+
+func TestDaemonSolicitedRA(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name: "net0",
+				// Make sure the unsolicited RA doesn't interfere
+				RAIntervalMilliseconds:         10000,
+				InitialRACount:                 ptr.To(0),
+				MinDelayBetweenRAsMilliseconds: ptr.To(1000),
+				RSRateLimitPerSecond:           ptr.To(1),
+				RSRateLimitBurst:               10,
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		s, err := reg.getSock("net0")
+		if !assert.NoError(ct, err) {
+			return
+		}
+		sock = s
+	}, time.Second*1, time.Millisecond*10)
+
+	var lastRA fakeRA
+
+	t.Run("Ensure RS from the unspecified address is replied with multicast RA", func(t *testing.T) {
+		since := time.Now()
+		sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: netip.MustParseAddr("::%net0"), hopLimit: 255}
+
+		lastRA = receiveRAs(t, sock, 1, time.Second*1)[0]
+		assertIntervalInRange(t, lastRA.tstamp.Sub(since), 0, maxRADelay+60*time.Millisecond)
+		assert.Empty(t, sock.txLLUnicastCh())
+	})
+
+	t.Run("Ensure multiple RSes are replied with a single multicast RA", func(t *testing.T) {
+		for _, from := range []string{"fe80::1%net0", "fe80::2%net0", "fe80::3%net0"} {
+			sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: netip.MustParseAddr(from), hopLimit: 255}
+		}
+
+		// MinDelayBetweenRAsMilliseconds must be respected
+		ra := receiveRAs(t, sock, 1, time.Second*2)[0]
+		assert.GreaterOrEqual(t, ra.tstamp.Sub(lastRA.tstamp), time.Second-60*time.Millisecond)
+
+		// No more RA should be sent
+		time.Sleep(maxRADelay + 100*time.Millisecond)
+		assert.Empty(t, sock.txMulticastCh())
+		assert.Empty(t, sock.txLLUnicastCh())
+
+		status := d.Status()
+		require.Len(t, status.Interfaces, 1)
+		assert.Equal(t, 2, status.Interfaces[0].CoalescedRS)
+		assert.Equal(t, 2, status.Interfaces[0].TxSolicitedRA)
+	})
+
+	t.Run("Ensure RSes exceeding the rate limit are dropped", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: netip.MustParseAddr("fe80::1%net0"), hopLimit: 255}
+		}
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := d.Status()
+			if !assert.Len(ct, status.Interfaces, 1) {
+				return
+			}
+			assert.GreaterOrEqual(ct, status.Interfaces[0].RateLimitedRS, 10)
+		}, time.Second*1, time.Millisecond*10)
+	})
+
+	t.Run("Ensure the reload doesn't refill the rate limiter", func(t *testing.T) {
+		limited := d.Status().Interfaces[0].RateLimitedRS
+
+		config.Interfaces[0].RSRateLimitBurst = 5
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancelTimeout()
+
+		err := d.Reload(timeout, config)
+		require.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: netip.MustParseAddr("fe80::1%net0"), hopLimit: 255}
+		}
+
+		// Only the tokens added since the previous subtest are left
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := d.Status()
+			if !assert.Len(ct, status.Interfaces, 1) {
+				return
+			}
+			assert.GreaterOrEqual(ct, status.Interfaces[0].RateLimitedRS, limited+3)
+		}, time.Second*1, time.Millisecond*10)
+	})
+}
+
+func TestDaemonMinDelayBetweenRAs(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name: "net0",
+				// Make sure the unsolicited RA doesn't interfere
+				RAIntervalMilliseconds:         10000,
+				MinDelayBetweenRAsMilliseconds: ptr.To(500),
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	reload := func(t *testing.T) {
+		config.Interfaces[0].CurrentHopLimit++
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancelTimeout()
+		require.NoError(t, d.Reload(timeout, config))
+	}
+
+	t.Run("Ensure the first RA after the reload is not delayed", func(t *testing.T) {
+		// The first RA after the start
+		ras := receiveRAs(t, sock, 1, time.Second*1)
+
+		// The reload restarts the advertisement with the immediate RA
+		reload(t)
+		ras = append(ras, receiveRAs(t, sock, 1, time.Second*1)...)
+		assert.Less(t, ras[1].tstamp.Sub(ras[0].tstamp), 500*time.Millisecond)
+
+		// The multicast RA answering the RS is still separated from
+		// it by MinDelayBetweenRAsMilliseconds
+		sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: netip.MustParseAddr("::%net0"), hopLimit: 255}
+		ras = append(ras, receiveRAs(t, sock, 1, time.Second*1)...)
+		assert.GreaterOrEqual(t, ras[2].tstamp.Sub(ras[1].tstamp), 500*time.Millisecond-10*time.Millisecond)
+	})
+
+	t.Run("Ensure the pending RS is answered after the reload", func(t *testing.T) {
+		sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: netip.MustParseAddr("fe80::1%net0"), hopLimit: 255}
+		time.Sleep(time.Millisecond * 10)
+		reload(t)
+
+		select {
+		case ra := <-sock.txLLUnicastCh():
+			assert.Equal(t, netip.MustParseAddr("fe80::1%net0"), ra.to)
+			assert.Equal(t, uint8(config.Interfaces[0].CurrentHopLimit), ra.msg.CurrentHopLimit)
+		case <-time.After(time.Second * 1):
+			require.Fail(t, "pending RS is not answered")
+		}
+	})
+}
+
+func TestDaemonUnicastOnly(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                   "net0",
+				RAIntervalMilliseconds: 100,
+				RouterLifetimeSeconds:  1800,
+				UnicastOnly:            true,
+				Clients:                []string{"fe80::1", "fe80::2"},
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	runDone := make(chan any)
+	go func() {
+		d.Run(ctx)
+		close(runDone)
+	}()
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	// Collects the unicast RAs sent within the duration
+	collectRAs := func(d time.Duration) []fakeRA {
+		ras := []fakeRA{}
+		timeout := time.After(d)
+		for {
+			select {
+			case ra := <-sock.txLLUnicastCh():
+				ras = append(ras, ra)
+			case <-timeout:
+				return ras
+			}
+		}
+	}
+
+	// Waits until the number of the solicited RAs reaches n
+	waitSolicited := func(t *testing.T, n int) {
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := d.Status()
+			if !assert.Len(ct, status.Interfaces, 1) {
+				return
+			}
+			assert.Equal(ct, n, status.Interfaces[0].TxSolicitedRA)
+		}, time.Second*2, time.Millisecond*10)
+	}
+
+	t.Run("Ensure the unsolicited RAs are sent to the clients", func(t *testing.T) {
+		ras := collectRAs(time.Millisecond * 500)
+
+		counts := map[netip.Addr]int{}
+		for _, ra := range ras {
+			counts[ra.to]++
+		}
+		require.Len(t, counts, 2)
+		require.GreaterOrEqual(t, counts[netip.MustParseAddr("fe80::1")], 2)
+		require.GreaterOrEqual(t, counts[netip.MustParseAddr("fe80::2")], 2)
+
+		require.Empty(t, sock.txMulticastCh())
+	})
+
+	t.Run("Ensure each RS is answered with the unicast RA", func(t *testing.T) {
+		for _, from := range []string{"fe80::3%net0", "fe80::4%net0", "fe80::4%net0", "::%net0"} {
+			sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: netip.MustParseAddr(from), hopLimit: 255}
+		}
+
+		waitSolicited(t, 2)
+
+		ras := collectRAs(maxRADelay + 100*time.Millisecond)
+		for _, ra := range ras {
+			require.NotEqual(t, netip.MustParseAddr("::%net0"), ra.to)
+		}
+
+		status := d.Status()
+		require.Len(t, status.Interfaces, 1)
+		require.Equal(t, 2, status.Interfaces[0].TxSolicitedRA)
+		require.Zero(t, status.Interfaces[0].CoalescedRS)
+		require.Empty(t, sock.txMulticastCh())
+	})
+
+	t.Run("Ensure RSes from non-clients are ignored with ClientsOnly", func(t *testing.T) {
+		config.Interfaces[0].ClientsOnly = true
+		config.Interfaces[0].CurrentHopLimit = 64
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		err := d.Reload(timeout, config)
+		require.NoError(t, err)
+		cancelTimeout()
+
+		// The new hop limit tells the reload is applied
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			select {
+			case ra := <-sock.txLLUnicastCh():
+				assert.Equal(ct, uint8(64), ra.msg.CurrentHopLimit)
+			default:
+				assert.Fail(ct, "RA is not sent yet")
+			}
+		}, time.Second*1, time.Millisecond*10)
+
+		sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: netip.MustParseAddr("fe80::3%net0"), hopLimit: 255}
+		sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: netip.MustParseAddr("fe80::1%net0"), hopLimit: 255}
+
+		waitSolicited(t, 3)
+
+		ras := collectRAs(maxRADelay + 100*time.Millisecond)
+		for _, ra := range ras {
+			require.NotEqual(t, netip.MustParseAddr("fe80::3%net0"), ra.to)
+		}
+
+		status := d.Status()
+		require.Len(t, status.Interfaces, 1)
+		require.Equal(t, 3, status.Interfaces[0].TxSolicitedRA)
+	})
+
+	t.Run("Ensure the final RAs are sent to the clients", func(t *testing.T) {
+		cancel()
+
+		select {
+		case <-runDone:
+		case <-time.After(time.Second * 2):
+			require.Fail(t, "daemon didn't stop in time")
+		}
+
+		final := map[netip.Addr]int{}
+		for _, ra := range collectRAs(time.Millisecond * 100) {
+			if ra.msg.RouterLifetime == 0 {
+				final[ra.to]++
+			}
+		}
+		require.Equal(t, map[netip.Addr]int{
+			netip.MustParseAddr("fe80::1"): 3,
+			netip.MustParseAddr("fe80::2"): 3,
+		}, final)
+
+		require.Empty(t, sock.txMulticastCh())
+	})
+}
+
+func TestDaemonLowPower(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "net0",
+				RAIntervalMilliseconds:         100,
+				MinDelayBetweenRAsMilliseconds: ptr.To(1000),
+				PowerProfile:                   "low-power",
+				RouterLifetimeSeconds:          65535,
+				AdvertisementInterval:          true,
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	var lastRA fakeRA
+
+	t.Run("Ensure the multicast RAs are rate-limited", func(t *testing.T) {
+		// The first RA is sent immediately
+		select {
+		case lastRA = <-sock.txMulticastCh():
+		case <-time.After(time.Second * 1):
+			require.Fail(t, "the first multicast RA is not sent")
+		}
+
+		// The low-power interval is advertised
+		found := false
+		for _, option := range lastRA.msg.Options {
+			if opt, ok := option.(*ndp.RawOption); ok && opt.Type == 7 {
+				require.Equal(t, uint32(10800000), binary.BigEndian.Uint32(opt.Value[2:6]))
+				found = true
+			}
+		}
+		require.True(t, found)
+
+		// The following ones are not sent with RAIntervalMilliseconds
+		time.Sleep(time.Millisecond * 500)
+		require.Empty(t, sock.txMulticastCh())
+	})
+
+	t.Run("Ensure each RS is answered with the unicast RA", func(t *testing.T) {
+		for _, from := range []string{"fe80::1%net0", "fe80::2%net0", "fe80::1%net0"} {
+			sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: netip.MustParseAddr(from), hopLimit: 255}
+		}
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := d.Status()
+			if !assert.Len(ct, status.Interfaces, 1) {
+				return
+			}
+			assert.Equal(ct, 2, status.Interfaces[0].TxSolicitedRA)
+		}, time.Second*2, time.Millisecond*10)
+
+		to := map[netip.Addr]bool{}
+		for i := 0; i < 2; i++ {
+			ra := <-sock.txLLUnicastCh()
+			to[ra.to] = true
+		}
+		require.Equal(t, map[netip.Addr]bool{
+			netip.MustParseAddr("fe80::1%net0"): true,
+			netip.MustParseAddr("fe80::2%net0"): true,
+		}, to)
+
+		status := d.Status()
+		require.Zero(t, status.Interfaces[0].CoalescedRS)
+		require.Empty(t, sock.txMulticastCh())
+	})
+
+	t.Run("Ensure RS from the unspecified address is answered with the rate-limited multicast RA", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: netip.MustParseAddr("::%net0"), hopLimit: 255}
+
+			// MinDelayBetweenRAsMilliseconds must be respected
+			ra := receiveRAs(t, sock, 1, time.Second*2)[0]
+			assert.GreaterOrEqual(t, ra.tstamp.Sub(lastRA.tstamp), time.Second-60*time.Millisecond)
+			lastRA = ra
+		}
+		require.Empty(t, sock.txLLUnicastCh())
+	})
+
+	t.Run("Ensure the reload doesn't send the multicast RA", func(t *testing.T) {
+		config.Interfaces[0].RouterLifetimeSeconds = 43200
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		err := d.Reload(timeout, config)
+		require.NoError(t, err)
+		cancelTimeout()
+
+		time.Sleep(time.Millisecond * 500)
+		require.Empty(t, sock.txMulticastCh())
+	})
+}
+
+func TestDaemonSplitRA(t *testing.T) {
+	key, keyPEM, certPEM := newTestSENDCredentials(t)
+
+	prefixes := []*PrefixConfig{}
+	for i := range 64 {
+		prefixes = append(prefixes, &PrefixConfig{
+			Prefix:     netip.PrefixFrom(netip.AddrFrom16([16]byte{0x20, 0x01, 0x0d, 0xb8, 0x00, byte(i)}), 64).String(),
+			OnLink:     true,
+			Autonomous: true,
+		})
+	}
+
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name: "net0",
+				// Make sure only the first unsolicited RA is sent
+				RAIntervalMilliseconds:         10000,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				InitialRACount:                 ptr.To(1),
+				RouterLifetimeSeconds:          1800,
+				MTU:                            1280,
+				Prefixes:                       prefixes,
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}, mtu: 1280})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	// Ensures the RAs share the header and SLLA, and carry all options
+	// in the original order
+	checkRAs := func(t *testing.T, ras []*ndp.RouterAdvertisement) {
+		require.Greater(t, len(ras), 1)
+
+		options := []ndp.Option{}
+		for _, ra := range ras {
+			header := *ra
+			header.Options = nil
+			require.Equal(t, ndp.RouterAdvertisement{
+				RouterSelectionPreference: ndp.Medium,
+				RouterLifetime:            time.Second * 1800,
+			}, header)
+
+			require.NotEmpty(t, ra.Options)
+			require.Equal(t, &ndp.LinkLayerAddress{
+				Direction: ndp.Source,
+				Addr:      net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66},
+			}, ra.Options[0])
+
+			options = append(options, ra.Options[1:]...)
+		}
+
+		require.Len(t, options, 65)
+		require.Equal(t, &ndp.MTU{MTU: 1280}, options[0])
+		for i, option := range options[1:] {
+			pi, ok := option.(*ndp.PrefixInformation)
+			require.True(t, ok)
+			require.Equal(t, prefixes[i].Prefix, netip.PrefixFrom(pi.Prefix, int(pi.PrefixLength)).String())
+		}
+	}
+
+	t.Run("Ensure the RA is split within the MTU", func(t *testing.T) {
+		ras := []*ndp.RouterAdvertisement{}
+		timeout := time.After(time.Millisecond * 500)
+	loop:
+		for {
+			select {
+			case ra := <-sock.txMulticastCh():
+				b, err := ndp.MarshalMessage(ra.msg)
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(b), 1280-40)
+				ras = append(ras, ra.msg)
+			case <-timeout:
+				break loop
+			}
+		}
+		checkRAs(t, ras)
+	})
+
+	t.Run("Ensure the signed RA is split within the MTU", func(t *testing.T) {
+		config.Interfaces[0].SEND = &SENDConfig{
+			PrivateKey:   keyPEM,
+			Certificates: []string{certPEM},
+		}
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		err := d.Reload(timeout, config)
+		require.NoError(t, err)
+		cancelTimeout()
+
+		ras := []*ndp.RouterAdvertisement{}
+		timeout2 := time.After(time.Millisecond * 500)
+	loop:
+		for {
+			select {
+			case raw := <-sock.txRawCh():
+				require.LessOrEqual(t, len(raw.b), 1280-40)
+				ra := verifySENDMessage(t, raw, key)
+
+				// Strip the CGA, Timestamp, and RSA Signature
+				// options
+				require.GreaterOrEqual(t, len(ra.Options), 3)
+				ra.Options = ra.Options[:len(ra.Options)-3]
+
+				ras = append(ras, ra)
+			case <-timeout2:
+				break loop
+			}
+		}
+		checkRAs(t, ras)
+	})
+}
+
+func TestDaemonInvalidRS(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name: "net0",
+				// Make sure the unsolicited RA doesn't interfere
+				RAIntervalMilliseconds: 10000,
+				InitialRACount:         ptr.To(0),
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		s, err := reg.getSock("net0")
+		if !assert.NoError(ct, err) {
+			return
+		}
+		sock = s
+	}, time.Second*1, time.Millisecond*10)
+
+	from := netip.MustParseAddr("fe80::1%net0")
+	lladdr := net.HardwareAddr{0x99, 0x88, 0x77, 0x66, 0x55, 0x44}
+	slla := &ndp.LinkLayerAddress{Direction: ndp.Source, Addr: lladdr}
+
+	t.Run("Ensure invalid RSes are dropped", func(t *testing.T) {
+		// Hop limit is not 255
+		sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: from, hopLimit: 64}
+
+		// ICMP code is not 0
+		sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: from, hopLimit: 255, code: 1}
+
+		// SLLA from the unspecified address
+		sock.rxCh() <- fakeRS{
+			msg:      &ndp.RouterSolicitation{Options: []ndp.Option{slla}},
+			from:     netip.MustParseAddr("::%net0"),
+			hopLimit: 255,
+		}
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := d.Status()
+			if !assert.Len(ct, status.Interfaces, 1) {
+				return
+			}
+			assert.Equal(ct, 1, status.Interfaces[0].InvalidHopLimitRS)
+			assert.Equal(ct, 1, status.Interfaces[0].InvalidCodeRS)
+			assert.Equal(ct, 1, status.Interfaces[0].InvalidSLLARS)
+		}, time.Second*1, time.Millisecond*10)
+
+		// None of them should be replied
+		time.Sleep(maxRADelay + 100*time.Millisecond)
+		assert.Empty(t, sock.txMulticastCh())
+		assert.Empty(t, sock.txLLUnicastCh())
+		assert.Equal(t, Running, d.Status().Interfaces[0].State)
+	})
+
+	t.Run("Ensure the RS with SLLA is answered with the unicast RA", func(t *testing.T) {
+		sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{Options: []ndp.Option{slla}}, from: from, hopLimit: 255}
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancelTimeout()
+		select {
+		case ra := <-sock.txLLUnicastCh():
+			require.Equal(t, from, ra.to)
+		case <-timeout.Done():
+			require.Fail(t, "timeout waiting for RA")
+		}
+	})
+}
+
+func TestDaemonNonEthernetLinks(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                   "tun0",
+				RAIntervalMilliseconds: 100,
+			},
+			{
+				Name:                   "ib0",
+				RAIntervalMilliseconds: 100,
+			},
+			{
+				Name:                   "lowpan0",
+				RAIntervalMilliseconds: 100,
+			},
+		},
+	}
+
+	// The 64-bit IEEE 802.15.4 link-layer address
+	lowpanAddr := net.HardwareAddr{0x02, 0x11, 0x22, 0xff, 0xfe, 0x33, 0x44, 0x55}
+
+	// The 20-byte IPoIB link-layer address
+	ibAddr := net.HardwareAddr{
+		0x80, 0x00, 0x00, 0x48, 0xfe, 0x80, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x02, 0xc9, 0x03, 0x00, 0x01, 0x02, 0x03,
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("tun0", "ib0", "lowpan0")
+	devWatcher.update("tun0", deviceState{isUp: true, v6LLAddrAssigned: true, addrs: []netip.Addr{netip.MustParseAddr("fe80::1")}})
+	devWatcher.update("ib0", deviceState{isUp: true, addr: ibAddr})
+	devWatcher.update("lowpan0", deviceState{isUp: true, addr: lowpanAddr})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var tunSock, ibSock, lowpanSock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		tunSock, err = reg.getSock("tun0")
+		if !assert.NoError(ct, err) {
+			return
+		}
+		ibSock, err = reg.getSock("ib0")
+		if !assert.NoError(ct, err) {
+			return
+		}
+		lowpanSock, err = reg.getSock("lowpan0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	t.Run("Ensure SLLA is omitted on the link without link-layer address", func(t *testing.T) {
+		ra := <-tunSock.txMulticastCh()
+		for _, option := range ra.msg.Options {
+			require.False(t, isSourceLinkLayerAddressOption(option))
+		}
+		_, err := ndp.MarshalMessage(ra.msg)
+		require.NoError(t, err)
+	})
+
+	t.Run("Ensure SLLA is encoded for the IPoIB address", func(t *testing.T) {
+		ra := <-ibSock.txMulticastCh()
+
+		b, err := ndp.MarshalMessage(ra.msg)
+		require.NoError(t, err)
+
+		// The SLLA comes first after the RA header with the two
+		// reserved bytes (RFC4391 Section 9.1.2)
+		require.Equal(t, append([]byte{1, 3, 0, 0}, ibAddr...), b[16:40])
+	})
+
+	t.Run("Ensure SLLA is padded for the 64-bit address", func(t *testing.T) {
+		ra := <-lowpanSock.txMulticastCh()
+
+		var slla *ndp.RawOption
+		for _, option := range ra.msg.Options {
+			if opt, ok := option.(*ndp.RawOption); ok && opt.Type == byte(ndp.Source) {
+				slla = opt
+			}
+		}
+		require.NotNil(t, slla, "Source Link-Layer Address option is not advertised")
+		require.Equal(t, uint8(2), slla.Length)
+		require.Equal(t, append(slices.Clone([]byte(lowpanAddr)), make([]byte, 6)...), slla.Value)
+
+		b, err := ndp.MarshalMessage(ra.msg)
+		require.NoError(t, err)
+
+		// The SLLA comes first after the RA header followed by the
+		// padding (RFC4861 Section 4.6.1)
+		require.Equal(t, append(append([]byte{1, 2}, lowpanAddr...), 0, 0, 0, 0, 0, 0), b[16:32])
+	})
+
+	t.Run("Ensure the RS with the IPoIB SLLA is answered", func(t *testing.T) {
+		// Make an RS with the IPoIB SLLA on the wire
+		b, err := ndp.MarshalMessage(&ndp.RouterSolicitation{})
+		require.NoError(t, err)
+		b = append(b, 1, 3, 0, 0)
+		b = append(b, ibAddr...)
+
+		rs, err := parseRS(b)
+		require.NoError(t, err)
+
+		from := netip.MustParseAddr("fe80::1%ib0")
+		ibSock.rxCh() <- fakeRS{msg: rs, from: from, hopLimit: 255}
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			select {
+			case ra := <-ibSock.txLLUnicastCh():
+				assert.Equal(ct, from, ra.to)
+			default:
+				assert.Fail(ct, "RA is not sent yet")
+			}
+		}, time.Second*1, time.Millisecond*10)
+	})
+}
+
+func TestDaemonLinkLocalAddress(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                   "wg0",
+				RAIntervalMilliseconds: 100,
+			},
+			{
+				Name:                   "wg1",
+				RAIntervalMilliseconds: 100,
+				LinkLocalAddress:       "fe80::2",
+			},
+		},
+	}
+
+	lladdr := netip.MustParseAddr("fe80::1")
+
+	reg := newFakeSockRegistry()
+
+	// The point-to-point links without the link-layer address and the
+	// link-local address
+	devWatcher := newFakeDeviceWatcher("wg0", "wg1")
+	devWatcher.update("wg0", deviceState{isUp: true})
+	devWatcher.update("wg1", deviceState{isUp: true})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	t.Run("Ensure the missing link-local address is reported", func(t *testing.T) {
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := d.Status()
+			if !assert.Len(ct, status.Interfaces, 2) {
+				return
+			}
+			assert.Equal(ct, Failing, status.Interfaces[0].State)
+			assert.Contains(ct, status.Interfaces[0].Message, "LinkLocalAddress")
+		}, time.Second*1, time.Millisecond*10)
+	})
+
+	t.Run("Ensure the removed interface is cleaned up while waiting for the address", func(t *testing.T) {
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			assert.Equal(ct, []netip.Addr{netip.MustParseAddr("fe80::2")}, devWatcher.assignedLinkLocalAddrs("wg1"))
+		}, time.Second*1, time.Millisecond*10)
+
+		config.Interfaces = config.Interfaces[:1]
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancelTimeout()
+		require.NoError(t, d.Reload(timeout, config))
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			assert.Empty(ct, devWatcher.assignedLinkLocalAddrs("wg1"))
+		}, time.Second*1, time.Millisecond*10)
+	})
+
+	t.Run("Ensure the link-local address is assigned by the reload while waiting for the address", func(t *testing.T) {
+		config.Interfaces[0].LinkLocalAddress = lladdr.String()
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancelTimeout()
+		require.NoError(t, d.Reload(timeout, config))
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			assert.Equal(ct, []netip.Addr{lladdr}, devWatcher.assignedLinkLocalAddrs("wg0"))
+		}, time.Second*1, time.Millisecond*10)
+	})
+
+	t.Run("Ensure the socket isn't created until the address appears", func(t *testing.T) {
+		require.Never(t, func() bool {
+			_, err := reg.getSock("wg0")
+			return err == nil
+		}, time.Millisecond*300, time.Millisecond*10)
+	})
+
+	t.Run("Ensure RAs are sent once the address appears", func(t *testing.T) {
+		devWatcher.update("wg0", deviceState{isUp: true, v6LLAddrAssigned: true, addrs: []netip.Addr{lladdr}})
+
+		var sock *fakeSock
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			sock, err = reg.getSock("wg0")
+			assert.NoError(ct, err)
+		}, time.Second*1, time.Millisecond*10)
+
+		receiveRAs(t, sock, 1, time.Second)
+	})
+
+	t.Run("Ensure the link-local address is removed on stop", func(t *testing.T) {
+		cancel()
+		<-done
+		require.Empty(t, devWatcher.assignedLinkLocalAddrs("wg0"))
+	})
+}
+
+func TestDaemonVirtualRouter(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                   "net0",
+				RAIntervalMilliseconds: 100,
+				VirtualRouter: &VirtualRouterConfig{
+					MACAddress:       "00:00:5e:00:02:01",
+					LinkLocalAddress: "fe80::1",
+				},
+			},
+		},
+	}
+
+	devState := deviceState{
+		isUp:             true,
+		v6LLAddrAssigned: true,
+		addr:             net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66},
+		addrs:            []netip.Addr{netip.MustParseAddr("fe80::1122:33ff:fe44:5566")},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", devState)
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	t.Run("Ensure no RA is sent without the virtual address", func(t *testing.T) {
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := d.Status()
+			if !assert.Len(ct, status.Interfaces, 1) {
+				return
+			}
+			assert.Equal(ct, Backup, status.Interfaces[0].State)
+			assert.Empty(ct, status.Interfaces[0].Message)
+		}, time.Second*1, time.Millisecond*10)
+
+		time.Sleep(time.Millisecond * 300)
+		require.Empty(t, sock.txMulticastCh())
+	})
+
+	t.Run("Ensure RAs are sent from the virtual address", func(t *testing.T) {
+		devState.addrs = append(devState.addrs, netip.MustParseAddr("fe80::1"))
+		devWatcher.update("net0", devState)
+
+		timeout := time.After(time.Second * 1)
+		select {
+		case ra := <-sock.txMulticastCh():
+			require.Equal(t, netip.MustParseAddr("fe80::1"), ra.from)
+			require.Contains(t, ra.msg.Options, &ndp.LinkLayerAddress{
+				Direction: ndp.Source,
+				Addr:      net.HardwareAddr{0x00, 0x00, 0x5e, 0x00, 0x02, 0x01},
+			})
+		case <-timeout:
+			require.Fail(t, "RA is not sent")
+		}
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			assert.Equal(ct, Running, d.Status().Interfaces[0].State)
+		}, time.Second*1, time.Millisecond*10)
+	})
+
+	t.Run("Ensure RS is answered from the virtual address", func(t *testing.T) {
+		from := netip.MustParseAddr("fe80::2%net0")
+		sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: from, hopLimit: 255}
+
+		timeout := time.After(time.Second * 1)
+		select {
+		case ra := <-sock.txLLUnicastCh():
+			require.Equal(t, from, ra.to)
+			require.Equal(t, netip.MustParseAddr("fe80::1"), ra.from)
+		case <-timeout:
+			require.Fail(t, "RA is not sent")
+		}
+	})
+
+	t.Run("Ensure the backup router stays silent", func(t *testing.T) {
+		devState.addrs = devState.addrs[:1]
+		devWatcher.update("net0", devState)
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			assert.Equal(ct, Backup, d.Status().Interfaces[0].State)
+		}, time.Second*1, time.Millisecond*10)
+
+		// Drain the RAs sent before the address is removed
+		time.Sleep(time.Millisecond * 100)
+		for len(sock.txMulticastCh()) > 0 {
+			<-sock.txMulticastCh()
+		}
+
+		from := netip.MustParseAddr("fe80::2%net0")
+		sock.rxCh() <- fakeRS{msg: &ndp.RouterSolicitation{}, from: from, hopLimit: 255}
+
+		time.Sleep(time.Millisecond * 1000)
+		require.Empty(t, sock.txMulticastCh())
+		require.Empty(t, sock.txLLUnicastCh())
+		require.Equal(t, Backup, d.Status().Interfaces[0].State)
+	})
+}
+
+func TestDaemonTrackRoute(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "net0",
+				RAIntervalMilliseconds:         1000,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				RouterLifetimeSeconds:          1800,
+				Preference:                     "high",
+				TrackRoute: &TrackRouteConfig{
+					Prefix: "::/0",
+				},
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	routeWatcher := newFakeRouteWatcher(true)
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+		withRouteWatcher(routeWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	// waitRA waits for the RA with the expected router lifetime and
+	// preference. The RA interval is long enough, so the RA must be sent
+	// by the route change rather than the periodic one.
+	waitRA := func(t *testing.T, lifetime time.Duration, preference ndp.Preference) {
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			select {
+			case ra := <-sock.txMulticastCh():
+				assert.Equal(ct, lifetime, ra.msg.RouterLifetime)
+				assert.Equal(ct, preference, ra.msg.RouterSelectionPreference)
+			default:
+				assert.Fail(ct, "RA is not sent yet")
+			}
+		}, time.Millisecond*500, time.Millisecond*10)
+	}
+
+	waitStatus := func(t *testing.T, state string) {
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := d.Status()
+			if !assert.Len(ct, status.Interfaces, 1) {
+				return
+			}
+			assert.Equal(ct, state, status.Interfaces[0].TrackedRoute)
+		}, time.Second*1, time.Millisecond*10)
+	}
+
+	t.Run("Ensure the default router is advertised while the route is present", func(t *testing.T) {
+		waitRA(t, time.Second*1800, ndp.High)
+		waitStatus(t, RoutePresent)
+	})
+
+	t.Run("Ensure the zero lifetime is advertised when the route is gone", func(t *testing.T) {
+		routeWatcher.update(false)
+		waitRA(t, 0, ndp.Medium)
+		waitStatus(t, RouteAbsent)
+	})
+
+	t.Run("Ensure the default router is restored when the route is back", func(t *testing.T) {
+		routeWatcher.update(true)
+		waitRA(t, time.Second*1800, ndp.High)
+		waitStatus(t, RoutePresent)
+	})
+
+	t.Run("Ensure the low preference is advertised when the route is gone", func(t *testing.T) {
+		config.Interfaces[0].TrackRoute.Action = "low-preference"
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancelTimeout()
+
+		err := d.Reload(timeout, config)
+		require.NoError(t, err)
+		waitRA(t, time.Second*1800, ndp.High)
+
+		routeWatcher.update(false)
+		waitRA(t, time.Second*1800, ndp.Low)
+		waitStatus(t, RouteAbsent)
+	})
+
+	t.Run("Ensure the status is cleared when the tracking is disabled", func(t *testing.T) {
+		config.Interfaces[0].TrackRoute = nil
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancelTimeout()
+
+		err := d.Reload(timeout, config)
+		require.NoError(t, err)
+		waitRA(t, time.Second*1800, ndp.High)
+		waitStatus(t, "")
+	})
+
+	t.Run("Ensure the withdrawal is advertised immediately in the low-power profile", func(t *testing.T) {
+		config.Interfaces[0].TrackRoute = &TrackRouteConfig{Prefix: "::/0"}
+		config.Interfaces[0].PowerProfile = "low-power"
+		config.Interfaces[0].LowPowerMulticastRAIntervalSeconds = 600
+		routeWatcher.update(true)
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancelTimeout()
+
+		err := d.Reload(timeout, config)
+		require.NoError(t, err)
+		waitStatus(t, RoutePresent)
+
+		// Once the next multicast RA is scheduled 600s later, all the
+		// RAs sent before the reload are in the channel. Drop them.
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := d.Status()
+			if !assert.Len(ct, status.Interfaces, 1) {
+				return
+			}
+			assert.Greater(ct, status.Interfaces[0].NextUnsolicitedRA, time.Now().Add(time.Second*300).Unix())
+		}, time.Second*1, time.Millisecond*10)
+		for len(sock.txMulticastCh()) > 0 {
+			<-sock.txMulticastCh()
+		}
+
+		routeWatcher.update(false)
+		waitRA(t, 0, ndp.Medium)
+		waitStatus(t, RouteAbsent)
+	})
+}
+
+func TestDaemonHealthCheck(t *testing.T) {
+	// The stand-in target of the TCP probe
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	// The exec probe passes while the file exists
+	healthFile := filepath.Join(t.TempDir(), "healthy")
+	require.NoError(t, os.WriteFile(healthFile, nil, 0o644))
+
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "net0",
+				RAIntervalMilliseconds:         600000,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				RouterLifetimeSeconds:          1800,
+				HealthCheck: &HealthCheckConfig{
+					Type:                 "tcp",
+					Target:               ln.Addr().String(),
+					IntervalMilliseconds: 100,
+					TimeoutMilliseconds:  100,
+					Rise:                 1,
+					Fall:                 2,
+				},
+			},
+			{
+				Name:                           "net1",
+				RAIntervalMilliseconds:         600000,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				RouterLifetimeSeconds:          1800,
+				Preference:                     "high",
+				HealthCheck: &HealthCheckConfig{
+					Type:                 "exec",
+					Command:              []string{"test", "-e", healthFile},
+					IntervalMilliseconds: 100,
+					TimeoutMilliseconds:  100,
+					Rise:                 3,
+					Fall:                 1,
+					Action:               "low-preference",
+				},
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0", "net1")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+	devWatcher.update("net1", deviceState{isUp: true, addr: net.HardwareAddr{0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var tcpSock, execSock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		tcpSock, err = reg.getSock("net0")
+		if !assert.NoError(ct, err) {
+			return
+		}
+		execSock, err = reg.getSock("net1")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	// waitRA waits for the RA with the expected router lifetime and
+	// preference. The RA interval is long enough, so the RA must be sent
+	// by the health state change rather than the periodic one.
+	waitRA := func(t *testing.T, sock *fakeSock, lifetime time.Duration, preference ndp.Preference) {
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			select {
+			case ra := <-sock.txMulticastCh():
+				assert.Equal(ct, lifetime, ra.msg.RouterLifetime)
+				assert.Equal(ct, preference, ra.msg.RouterSelectionPreference)
+			default:
+				assert.Fail(ct, "RA is not sent yet")
+			}
+		}, time.Second*1, time.Millisecond*10)
+	}
+
+	healthCheckStatus := func(ct *assert.CollectT, name string) *HealthCheckStatus {
+		for _, iface := range d.Status().Interfaces {
+			if iface.Name == name && iface.HealthCheck != nil {
+				return iface.HealthCheck
+			}
+		}
+		assert.Fail(ct, "health check status is not reported")
+		return &HealthCheckStatus{}
+	}
+
+	t.Run("Ensure the default router is advertised while the probes pass", func(t *testing.T) {
+		waitRA(t, tcpSock, time.Second*1800, ndp.Medium)
+		waitRA(t, execSock, time.Second*1800, ndp.High)
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := healthCheckStatus(ct, "net0")
+			assert.Equal(ct, Healthy, status.State)
+			assert.Positive(ct, status.ConsecutiveSuccesses)
+			assert.NotZero(ct, status.LastProbe)
+		}, time.Second*1, time.Millisecond*10)
+	})
+
+	t.Run("Ensure the zero lifetime is advertised when the TCP probe fails", func(t *testing.T) {
+		require.NoError(t, ln.Close())
+
+		waitRA(t, tcpSock, 0, ndp.Medium)
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := healthCheckStatus(ct, "net0")
+			assert.Equal(ct, Unhealthy, status.State)
+			assert.GreaterOrEqual(ct, status.ConsecutiveFailures, 2)
+			assert.NotEmpty(ct, status.Message)
+		}, time.Second*1, time.Millisecond*10)
+	})
+
+	t.Run("Ensure the low preference is advertised when the exec probe fails", func(t *testing.T) {
+		require.NoError(t, os.Remove(healthFile))
+
+		waitRA(t, execSock, time.Second*1800, ndp.Low)
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			assert.Equal(ct, Unhealthy, healthCheckStatus(ct, "net1").State)
+		}, time.Second*1, time.Millisecond*10)
+	})
+
+	t.Run("Ensure the default router is restored after the rise count", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(healthFile, nil, 0o644))
+
+		waitRA(t, execSock, time.Second*1800, ndp.High)
+
+		status := d.Status()
+		for _, iface := range status.Interfaces {
+			if iface.Name == "net1" {
+				require.Equal(t, Healthy, iface.HealthCheck.State)
+				require.GreaterOrEqual(t, iface.HealthCheck.ConsecutiveSuccesses, 3)
+			}
+		}
+	})
+
+	t.Run("Ensure the withdrawal is advertised immediately in the low-power profile", func(t *testing.T) {
+		// The low-power interval is longer than RAIntervalMilliseconds to
+		// tell the schedule after the reload from the one before it.
+		config.Interfaces[1].PowerProfile = "low-power"
+		config.Interfaces[1].LowPowerMulticastRAIntervalSeconds = 1200
+		config.Interfaces[1].RouterLifetimeSeconds = 3600
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancelTimeout()
+
+		err := d.Reload(timeout, config)
+		require.NoError(t, err)
+
+		// Once the next multicast RA is scheduled 1200s later, all the
+		// RAs sent before the reload are in the channel. Drop them.
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			for _, iface := range d.Status().Interfaces {
+				if iface.Name == "net1" {
+					assert.Greater(ct, iface.NextUnsolicitedRA, time.Now().Add(time.Second*900).Unix())
+					assert.Equal(ct, Healthy, healthCheckStatus(ct, "net1").State)
+					return
+				}
+			}
+			assert.Fail(ct, "net1 is not reported")
+		}, time.Second*1, time.Millisecond*10)
+		for len(execSock.txMulticastCh()) > 0 {
+			<-execSock.txMulticastCh()
+		}
+
+		require.NoError(t, os.Remove(healthFile))
+		waitRA(t, execSock, time.Second*3600, ndp.Low)
+	})
+}
+
+func TestDaemonRouteSource(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "net0",
+				RAIntervalMilliseconds:         1000,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				Routes: []*RouteConfig{
+					{
+						Prefix:          "2001:db8:5::/48",
+						LifetimeSeconds: 100,
+						Preference:      "high",
+					},
+				},
+				RouteSource: &RouteSourceConfig{
+					Protocols:  []string{"bgp"},
+					Prefixes:   []string{"2001:db8::/32"},
+					Preference: "low",
+				},
+			},
+		},
+	}
+
+	route := func(dst string, protocol netlink.RouteProtocol, table, linkIndex int) netlink.Route {
+		_, ipnet, err := net.ParseCIDR(dst)
+		require.NoError(t, err)
+		return netlink.Route{
+			Family:    netlink.FAMILY_V6,
+			Type:      unix.RTN_UNICAST,
+			Dst:       ipnet,
+			Protocol:  protocol,
+			Table:     table,
+			LinkIndex: linkIndex,
+		}
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}, index: 2})
+
+	routeWatcher := newFakeRouteWatcher(true)
+	routeWatcher.updateRoutes([]netlink.Route{
+		// Advertised
+		route("2001:db8:1::/48", unix.RTPROT_BGP, 254, 3),
+		// Two routes with the different metrics are advertised once
+		route("2001:db8:2::/48", unix.RTPROT_BGP, 254, 3),
+		route("2001:db8:2::/48", unix.RTPROT_BGP, 254, 4),
+		// Different protocol
+		route("2001:db8:3::/48", unix.RTPROT_STATIC, 254, 3),
+		// Through the advertising interface
+		route("2001:db8:4::/48", unix.RTPROT_BGP, 254, 2),
+		// Configured in Routes
+		route("2001:db8:5::/48", unix.RTPROT_BGP, 254, 3),
+		// Different table
+		route("2001:db8:6::/48", unix.RTPROT_BGP, 100, 3),
+		// Out of the prefixes
+		route("2001:db9::/48", unix.RTPROT_BGP, 254, 3),
+		// Link-local
+		route("fe80::/64", unix.RTPROT_BGP, 254, 3),
+	})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+		withRouteWatcher(routeWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	routeOptions := func(ra *fakeRA) []*ndp.RouteInformation {
+		options := []*ndp.RouteInformation{}
+		for _, option := range ra.msg.Options {
+			if rio, ok := option.(*ndp.RouteInformation); ok {
+				options = append(options, rio)
+			}
+		}
+		return options
+	}
+
+	t.Run("Ensure the matching routes are advertised", func(t *testing.T) {
+		ra := <-sock.txMulticastCh()
+		require.Equal(t, []*ndp.RouteInformation{
+			{
+				PrefixLength:  48,
+				Preference:    ndp.High,
+				RouteLifetime: time.Second * 100,
+				Prefix:        netip.MustParseAddr("2001:db8:5::"),
+			},
+			{
+				PrefixLength:  48,
+				Preference:    ndp.Low,
+				RouteLifetime: time.Second * 1800,
+				Prefix:        netip.MustParseAddr("2001:db8:1::"),
+			},
+			{
+				PrefixLength:  48,
+				Preference:    ndp.Low,
+				RouteLifetime: time.Second * 1800,
+				Prefix:        netip.MustParseAddr("2001:db8:2::"),
+			},
+		}, routeOptions(&ra))
+
+		require.Equal(t, []string{"2001:db8:1::/48", "2001:db8:2::/48", "2001:db8:5::/48"}, d.Status().Interfaces[0].SourcedRoutes)
+	})
+
+	t.Run("Ensure the RA is sent when the routes change", func(t *testing.T) {
+		routeWatcher.updateRoutes([]netlink.Route{
+			route("2001:db8:2::/48", unix.RTPROT_BGP, 254, 3),
+			route("2001:db8:7::/48", unix.RTPROT_BGP, 254, 3),
+		})
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			select {
+			case ra := <-sock.txMulticastCh():
+				// The withdrawn route is deprecated
+				assert.Equal(ct, []*ndp.RouteInformation{
+					{
+						PrefixLength:  48,
+						Preference:    ndp.High,
+						RouteLifetime: time.Second * 100,
+						Prefix:        netip.MustParseAddr("2001:db8:5::"),
+					},
+					{
+						PrefixLength:  48,
+						Preference:    ndp.Low,
+						RouteLifetime: time.Second * 1800,
+						Prefix:        netip.MustParseAddr("2001:db8:2::"),
+					},
+					{
+						PrefixLength:  48,
+						Preference:    ndp.Low,
+						RouteLifetime: time.Second * 1800,
+						Prefix:        netip.MustParseAddr("2001:db8:7::"),
+					},
+					{
+						PrefixLength: 48,
+						Preference:   ndp.Low,
+						Prefix:       netip.MustParseAddr("2001:db8:1::"),
+					},
+				}, routeOptions(&ra))
+			default:
+				assert.Fail(ct, "RA is not sent yet")
+			}
+		}, time.Millisecond*500, time.Millisecond*10)
+
+		require.Equal(t, []string{"2001:db8:2::/48", "2001:db8:7::/48"}, d.Status().Interfaces[0].SourcedRoutes)
+	})
+}
+
+func TestDaemonTriggeredRA(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "net0",
+				RAIntervalMilliseconds:         600000,
+				InitialRAIntervalMilliseconds:  200,
+				MinDelayBetweenRAsMilliseconds: ptr.To(500),
+				RouteSource: &RouteSourceConfig{
+					Protocols: []string{"bgp"},
+				},
+			},
+		},
+	}
+
+	// routes creates the BGP routes 2001:db8:1::/48 to 2001:db8:n::/48
+	routes := func(n int) []netlink.Route {
+		ret := []netlink.Route{}
+		for i := 1; i <= n; i++ {
+			ret = append(ret, netlink.Route{
+				Family:   netlink.FAMILY_V6,
+				Type:     unix.RTN_UNICAST,
+				Dst:      &net.IPNet{IP: net.ParseIP(fmt.Sprintf("2001:db8:%x::", i)), Mask: net.CIDRMask(48, 128)},
+				Protocol: unix.RTPROT_BGP,
+				Table:    254,
+			})
+		}
+		return ret
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	routeWatcher := newFakeRouteWatcher(true)
+	routeWatcher.updateRoutes(routes(1))
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+		withRouteWatcher(routeWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	numRoutes := func(ra fakeRA) int {
+		n := 0
+		for _, option := range ra.msg.Options {
+			if _, ok := option.(*ndp.RouteInformation); ok {
+				n++
+			}
+		}
+		return n
+	}
+
+	// churn updates the routes one by one and returns the single RA
+	// advertising the last set of the routes
+	churn := func(t *testing.T, from, to int) fakeRA {
+		for n := from; n <= to; n++ {
+			routeWatcher.updateRoutes(routes(n))
+		}
+
+		ra := receiveRAs(t, sock, 1, time.Second)[0]
+		require.Equal(t, to, numRoutes(ra))
+
+		// No more RA for the churn. The initial RAs would follow if
+		// the advertisement is restarted.
+		time.Sleep(time.Millisecond * 700)
+		require.Empty(t, sock.txMulticastCh())
+
+		return ra
+	}
+
+	initial := receiveRAs(t, sock, 3, time.Second*2)
+	last := initial[len(initial)-1]
+
+	t.Run("Ensure the burst of the changes is advertised with a single RA", func(t *testing.T) {
+		ra := churn(t, 2, 10)
+		require.GreaterOrEqual(t, ra.tstamp.Sub(last.tstamp), time.Millisecond*500)
+	})
+
+	t.Run("Ensure the triggered RAs don't restart the initial RAs", func(t *testing.T) {
+		churn(t, 11, 20)
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			status := d.Status()
+			if !assert.Len(ct, status.Interfaces, 1) {
+				return
+			}
+			assert.Equal(ct, Running, status.Interfaces[0].State)
+			assert.Len(ct, status.Interfaces[0].SourcedRoutes, 20)
+		}, time.Second*1, time.Millisecond*10)
+	})
+}
+
+func TestDaemonAutoPrefix(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "net0",
+				RAIntervalMilliseconds:         1000,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				Prefixes: []*PrefixConfig{
+					{
+						Prefix:              "::/64",
+						Autonomous:          true,
+						UseAddressLifetimes: true,
+					},
+					{
+						Prefix: "2001:db8:3::/64",
+						OnLink: true,
+					},
+				},
+			},
+		},
+	}
+
+	now := time.Now()
+	devState := deviceState{
+		isUp: true,
+		addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66},
+		globalAddrs: []deviceAddr{
+			// Infinite lifetimes
+			{prefix: netip.MustParsePrefix("2001:db8:1::1/64")},
+			// Finite lifetimes
+			{
+				prefix:         netip.MustParsePrefix("fd00:1::1/64"),
+				validUntil:     now.Add(time.Second * 1000),
+				preferredUntil: now.Add(time.Second * 500),
+			},
+			// Different prefix length
+			{prefix: netip.MustParsePrefix("2001:db8:2::1/56")},
+			// Configured explicitly
+			{prefix: netip.MustParsePrefix("2001:db8:3::1/64")},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", devState)
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	prefixOptions := func(ra *fakeRA) map[netip.Prefix]*ndp.PrefixInformation {
+		options := map[netip.Prefix]*ndp.PrefixInformation{}
+		for _, option := range ra.msg.Options {
+			if pi, ok := option.(*ndp.PrefixInformation); ok {
+				options[netip.PrefixFrom(pi.Prefix, int(pi.PrefixLength))] = pi
+			}
+		}
+		return options
+	}
+
+	t.Run("Ensure the prefixes of the addresses are advertised", func(t *testing.T) {
+		ra := <-sock.txMulticastCh()
+		options := prefixOptions(&ra)
+		require.Len(t, options, 3)
+
+		configured := options[netip.MustParsePrefix("2001:db8:3::/64")]
+		require.NotNil(t, configured)
+		require.True(t, configured.OnLink)
+		require.False(t, configured.AutonomousAddressConfiguration)
+
+		infinite := options[netip.MustParsePrefix("2001:db8:1::/64")]
+		require.NotNil(t, infinite)
+		require.True(t, infinite.AutonomousAddressConfiguration)
+		require.Equal(t, ndp.Infinity, infinite.ValidLifetime)
+		require.Equal(t, ndp.Infinity, infinite.PreferredLifetime)
+
+		finite := options[netip.MustParsePrefix("fd00:1::/64")]
+		require.NotNil(t, finite)
+		require.InDelta(t, time.Second*1000, finite.ValidLifetime, float64(time.Second*2))
+		require.InDelta(t, time.Second*500, finite.PreferredLifetime, float64(time.Second*2))
+
+		require.Equal(t, []*DerivedPrefixStatus{
+			{Prefix: "2001:db8:1::/64", Interface: "net0", Address: "2001:db8:1::1/64"},
+			{Prefix: "fd00:1::/64", Interface: "net0", Address: "fd00:1::1/64"},
+		}, d.Status().Interfaces[0].DerivedPrefixes)
+	})
+
+	t.Run("Ensure the removed prefix is deprecated", func(t *testing.T) {
+		devState.globalAddrs = slices.Delete(slices.Clone(devState.globalAddrs), 1, 2)
+		devWatcher.update("net0", devState)
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			select {
+			case ra := <-sock.txMulticastCh():
+				deprecated := prefixOptions(&ra)[netip.MustParsePrefix("fd00:1::/64")]
+				if !assert.NotNil(ct, deprecated) {
+					return
+				}
+				// The valid lifetime shorter than two hours is
+				// kept as is
+				assert.InDelta(ct, time.Second*1000, deprecated.ValidLifetime, float64(time.Second*2))
+				assert.Zero(ct, deprecated.PreferredLifetime)
+			default:
+				assert.Fail(ct, "RA is not sent yet")
+			}
+		}, time.Millisecond*500, time.Millisecond*10)
+
+		require.Equal(t, []*DerivedPrefixStatus{
+			{Prefix: "2001:db8:1::/64", Interface: "net0", Address: "2001:db8:1::1/64"},
+		}, d.Status().Interfaces[0].DerivedPrefixes)
+	})
+}
+
+func TestDaemonUpstreamPrefix(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "lan0",
+				RAIntervalMilliseconds:         1000,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				Prefixes: []*PrefixConfig{
+					{
+						Prefix:            "::/64",
+						OnLink:            true,
+						Autonomous:        true,
+						UpstreamInterface: "wan0",
+						SubnetID:          1,
+					},
+				},
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("lan0", "wan0")
+	devWatcher.update("lan0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("lan0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	prefixOptions := func(ra *fakeRA) map[netip.Prefix]*ndp.PrefixInformation {
+		options := map[netip.Prefix]*ndp.PrefixInformation{}
+		for _, option := range ra.msg.Options {
+			if pi, ok := option.(*ndp.PrefixInformation); ok {
+				options[netip.PrefixFrom(pi.Prefix, int(pi.PrefixLength))] = pi
+			}
+		}
+		return options
+	}
+
+	t.Run("Ensure no prefix is advertised without upstream address", func(t *testing.T) {
+		ra := <-sock.txMulticastCh()
+		require.Empty(t, prefixOptions(&ra))
+	})
+
+	t.Run("Ensure the sub-netted prefix of the upstream address is advertised", func(t *testing.T) {
+		now := time.Now()
+		devWatcher.update("wan0", deviceState{
+			isUp: true,
+			globalAddrs: []deviceAddr{
+				{
+					prefix:         netip.MustParsePrefix("2001:db8:0:ff00::1/56"),
+					validUntil:     now.Add(time.Second * 3600),
+					preferredUntil: now.Add(time.Second * 1800),
+				},
+				// Too long to be sub-netted
+				{prefix: netip.MustParsePrefix("2001:db8:1::1/128")},
+			},
+		})
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			select {
+			case ra := <-sock.txMulticastCh():
+				options := prefixOptions(&ra)
+				if !assert.Len(ct, options, 1) {
+					return
+				}
+				pi := options[netip.MustParsePrefix("2001:db8:0:ff01::/64")]
+				if !assert.NotNil(ct, pi) {
+					return
+				}
+				assert.True(ct, pi.OnLink)
+				assert.True(ct, pi.AutonomousAddressConfiguration)
+				// Capped by the upstream address lifetimes
+				assert.InDelta(ct, time.Second*3600, pi.ValidLifetime, float64(time.Second*2))
+				assert.InDelta(ct, time.Second*1800, pi.PreferredLifetime, float64(time.Second*2))
+			default:
+				assert.Fail(ct, "RA is not sent yet")
+			}
+		}, time.Millisecond*500, time.Millisecond*10)
+
+		require.Equal(t, []*DerivedPrefixStatus{
+			{Prefix: "2001:db8:0:ff01::/64", Interface: "wan0", Address: "2001:db8:0:ff00::1/56"},
+		}, d.Status().Interfaces[0].DerivedPrefixes)
+	})
+
+	t.Run("Ensure the prefix follows the upstream address change", func(t *testing.T) {
+		devWatcher.update("wan0", deviceState{
+			isUp: true,
+			globalAddrs: []deviceAddr{
+				{prefix: netip.MustParsePrefix("2001:db8:0:aa00::1/56")},
+			},
+		})
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			select {
+			case ra := <-sock.txMulticastCh():
+				options := prefixOptions(&ra)
+				pi := options[netip.MustParsePrefix("2001:db8:0:aa01::/64")]
+				if !assert.NotNil(ct, pi) {
+					return
+				}
+				// The configured lifetimes are used as is
+				assert.Equal(ct, time.Second*2592000, pi.ValidLifetime)
+				assert.Equal(ct, time.Second*604800, pi.PreferredLifetime)
+
+				// The old prefix is deprecated
+				deprecated := options[netip.MustParsePrefix("2001:db8:0:ff01::/64")]
+				if !assert.NotNil(ct, deprecated) {
+					return
+				}
+				assert.InDelta(ct, time.Second*3600, deprecated.ValidLifetime, float64(time.Second*2))
+				assert.Zero(ct, deprecated.PreferredLifetime)
+			default:
+				assert.Fail(ct, "RA is not sent yet")
+			}
+		}, time.Millisecond*500, time.Millisecond*10)
+
+		require.Equal(t, []*DerivedPrefixStatus{
+			{Prefix: "2001:db8:0:aa01::/64", Interface: "wan0", Address: "2001:db8:0:aa00::1/56"},
+		}, d.Status().Interfaces[0].DerivedPrefixes)
+	})
+}
+
+func TestDaemonSelfRDNSS(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "net0",
+				RAIntervalMilliseconds:         1000,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				RDNSSes: []*RDNSSConfig{
+					{
+						LifetimeSeconds: 100,
+						Addresses:       []string{"self", "2001:db8::53"},
+					},
+				},
+			},
+		},
+	}
+
+	devState := deviceState{
+		isUp:  true,
+		addr:  net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66},
+		addrs: []netip.Addr{netip.MustParseAddr("fe80::1")},
+		globalAddrs: []deviceAddr{
+			{prefix: netip.MustParsePrefix("2001:db8:1::1/64")},
+			{prefix: netip.MustParsePrefix("fd00:1::1/64")},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", devState)
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	// Lifetimes of the RDNSS addresses in the RA
+	rdnssLifetimes := func(ra *fakeRA) map[netip.Addr]time.Duration {
+		lifetimes := map[netip.Addr]time.Duration{}
+		for _, option := range ra.msg.Options {
+			if rdnss, ok := option.(*ndp.RecursiveDNSServer); ok {
+				for _, addr := range rdnss.Servers {
+					lifetimes[addr] = rdnss.Lifetime
+				}
+			}
+		}
+		return lifetimes
+	}
+
+	t.Run("Ensure the ULA address is advertised", func(t *testing.T) {
+		ra := <-sock.txMulticastCh()
+		require.Equal(t, map[netip.Addr]time.Duration{
+			netip.MustParseAddr("fd00:1::1"):    time.Second * 100,
+			netip.MustParseAddr("2001:db8::53"): time.Second * 100,
+		}, rdnssLifetimes(&ra))
+	})
+
+	t.Run("Ensure the new address is advertised and the old one is deprecated", func(t *testing.T) {
+		devState.globalAddrs = []deviceAddr{
+			{prefix: netip.MustParsePrefix("2001:db8:1::1/64")},
+		}
+		devWatcher.update("net0", devState)
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			select {
+			case ra := <-sock.txMulticastCh():
+				assert.Equal(ct, map[netip.Addr]time.Duration{
+					netip.MustParseAddr("2001:db8:1::1"): time.Second * 100,
+					netip.MustParseAddr("2001:db8::53"):  time.Second * 100,
+					netip.MustParseAddr("fd00:1::1"):     0,
+				}, rdnssLifetimes(&ra))
+			default:
+				assert.Fail(ct, "RA is not sent yet")
+			}
+		}, time.Millisecond*500, time.Millisecond*10)
+	})
+
+	t.Run("Ensure the link-local address is advertised without the global address", func(t *testing.T) {
+		devState.globalAddrs = nil
+		devWatcher.update("net0", devState)
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			select {
+			case ra := <-sock.txMulticastCh():
+				lifetimes := rdnssLifetimes(&ra)
+				assert.Equal(ct, time.Second*100, lifetimes[netip.MustParseAddr("fe80::1")])
+				assert.Contains(ct, lifetimes, netip.MustParseAddr("2001:db8:1::1"))
+				assert.Zero(ct, lifetimes[netip.MustParseAddr("2001:db8:1::1")])
+			default:
+				assert.Fail(ct, "RA is not sent yet")
+			}
+		}, time.Millisecond*500, time.Millisecond*10)
+	})
+}
+
+// parsePvDOption parses the PvD option and returns the sequence number and
+// the nested message. The nested message has the RA header only when the R
+// flag is set.
+func parsePvDOption(t *testing.T, option *ndp.RawOption) (byte, uint16, *ndp.RouterAdvertisement) {
+	t.Helper()
+
+	require.Equal(t, uint8(21), option.Type)
+
+	flags := option.Value[0]
+	seq := uint16(option.Value[2])<<8 | uint16(option.Value[3])
+
+	// Skip the FQDN and the padding
+	i := 4
+	for option.Value[i] != 0 {
+		i += int(option.Value[i]) + 1
+	}
+	i++
+	if r := (i + 2) % 8; r != 0 {
+		i += 8 - r
+	}
+
+	b := option.Value[i:]
+	if flags&0x20 == 0 {
+		// Prepend the dummy RA header
+		b = append([]byte{134, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, b...)
+	}
+
+	msg, err := ndp.ParseMessage(b)
+	require.NoError(t, err)
+
+	return flags, seq, msg.(*ndp.RouterAdvertisement)
+}
+
+func TestDaemonPvD(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "net0",
+				RAIntervalMilliseconds:         100,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				RouterLifetimeSeconds:          1800,
+				PvDs: []*PvDConfig{
+					{
+						FQDN:           "pvd.example.com",
+						HTTP:           true,
+						Delay:          3,
+						SequenceNumber: 10,
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix:     "fd00:1::/64",
+								OnLink:     true,
+								Autonomous: true,
+							},
+						},
+						RDNSSes: []*RDNSSConfig{
+							{
+								LifetimeSeconds: 300,
+								Addresses:       []string{"fd00:1::53"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	routeWatcher := newFakeRouteWatcher(true)
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+		withRouteWatcher(routeWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		s, err := reg.getSock("net0")
+		if !assert.NoError(ct, err) {
+			return
+		}
+		sock = s
+	}, time.Second*1, time.Millisecond*10)
+
+	// Receive the RA and find the PvD option
+	receivePvDOption := func(t *testing.T) *ndp.RawOption {
+		ra := receiveRAs(t, sock, 1, time.Second)[0]
+		for _, option := range ra.msg.Options {
+			if opt, ok := option.(*ndp.RawOption); ok && opt.Type == 21 {
+				return opt
+			}
+		}
+		require.Fail(t, "PvD option is not advertised")
+		return nil
+	}
+
+	reload := func(t *testing.T) {
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancelTimeout()
+		require.NoError(t, d.Reload(timeout, config))
+	}
+
+	// Waits until the PvD option with the expected sequence number is
+	// advertised
+	waitSequence := func(t *testing.T, expected uint16) *ndp.RawOption {
+		deadline := time.Now().Add(time.Second * 3)
+		for time.Now().Before(deadline) {
+			option := receivePvDOption(t)
+			if _, seq, _ := parsePvDOption(t, option); seq == expected {
+				return option
+			}
+		}
+		require.Failf(t, "timeout waiting for the sequence number", "expected %d", expected)
+		return nil
+	}
+
+	t.Run("Ensure the PvD option is advertised", func(t *testing.T) {
+		option := receivePvDOption(t)
+		require.Equal(t, byte(0x80), option.Value[0], "Only H flag must be set")
+		require.Equal(t, byte(3), option.Value[1], "Delay must be 3")
+
+		// FQDN is encoded in the DNS wire format
+		require.Equal(t, []byte{3, 'p', 'v', 'd', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0}, option.Value[4:21])
+
+		_, seq, msg := parsePvDOption(t, option)
+		require.Equal(t, uint16(10), seq)
+		require.Len(t, msg.Options, 2)
+
+		prefix, ok := msg.Options[0].(*ndp.PrefixInformation)
+		require.True(t, ok)
+		require.Equal(t, netip.MustParseAddr("fd00:1::"), prefix.Prefix)
+
+		rdnss, ok := msg.Options[1].(*ndp.RecursiveDNSServer)
+		require.True(t, ok)
+		require.Equal(t, []netip.Addr{netip.MustParseAddr("fd00:1::53")}, rdnss.Servers)
+	})
+
+	t.Run("Ensure the sequence number is kept when the PvD is not changed", func(t *testing.T) {
+		config.Interfaces[0].CurrentHopLimit = 64
+		reload(t)
+
+		// Skip the RAs sent before the reload
+		time.Sleep(time.Millisecond * 200)
+		for len(sock.txMulticastCh()) > 0 {
+			<-sock.txMulticastCh()
+		}
+
+		_, seq, _ := parsePvDOption(t, receivePvDOption(t))
+		require.Equal(t, uint16(10), seq)
+	})
+
+	t.Run("Ensure the sequence number is bumped when the PvD is changed", func(t *testing.T) {
+		config.Interfaces[0].PvDs[0].Routes = []*RouteConfig{
+			{
+				Prefix:          "fd00:2::/64",
+				LifetimeSeconds: 100,
+			},
+		}
+		reload(t)
+
+		_, _, msg := parsePvDOption(t, waitSequence(t, 11))
+		require.Len(t, msg.Options, 3)
+	})
+
+	t.Run("Ensure the sequence number in the configuration is respected", func(t *testing.T) {
+		config.Interfaces[0].PvDs[0].SequenceNumber = 100
+		reload(t)
+		waitSequence(t, 100)
+	})
+
+	t.Run("Ensure the RA header is included with R flag", func(t *testing.T) {
+		config.Interfaces[0].PvDs[0].RAHeader = true
+		reload(t)
+
+		flags, _, msg := parsePvDOption(t, waitSequence(t, 101))
+		require.Equal(t, byte(0xa0), flags)
+		require.Equal(t, uint8(64), msg.CurrentHopLimit)
+		require.Equal(t, time.Second*1800, msg.RouterLifetime)
+	})
+
+	t.Run("Ensure the withdrawn default router is embedded in the RA header", func(t *testing.T) {
+		config.Interfaces[0].TrackRoute = &TrackRouteConfig{Prefix: "::/0"}
+		reload(t)
+		waitSequence(t, 101)
+
+		routeWatcher.update(false)
+
+		// The content of the PvD option has changed
+		_, _, msg := parsePvDOption(t, waitSequence(t, 102))
+		require.Zero(t, msg.RouterLifetime)
+	})
+}
+
+func TestDaemonDHCPv6PDPreferred(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                   "net0",
+				RAIntervalMilliseconds: 100,
+				ZeroLifetimesOnStop:    true,
+				Prefixes: []*PrefixConfig{
+					{
+						Prefix:                   "fd00::/64",
+						OnLink:                   true,
+						Autonomous:               true,
+						PreferredLifetimeSeconds: ptr.To(86400),
+						ValidLifetimeSeconds:     ptr.To(172800),
+					},
+					{
+						Prefix:                   "fd00:1::/64",
+						OnLink:                   true,
+						Autonomous:               true,
+						DHCPv6PDPreferred:        true,
+						PreferredLifetimeSeconds: ptr.To(86400),
+						ValidLifetimeSeconds:     ptr.To(172800),
+					},
+				},
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	runDone := make(chan any)
+	go func() {
+		d.Run(ctx)
+		close(runDone)
+	}()
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	// Returns the Prefix Information options in the RA. The options with
+	// the P flag are parsed by ndp as well to make sure they are valid.
+	prefixOptions := func(t *testing.T, msg *ndp.RouterAdvertisement) ([]*ndp.PrefixInformation, []*ndp.RawOption) {
+		pis := []*ndp.PrefixInformation{}
+		raws := []*ndp.RawOption{}
+		for _, option := range msg.Options {
+			switch opt := option.(type) {
+			case *ndp.PrefixInformation:
+				pis = append(pis, opt)
+			case *ndp.RawOption:
+				if opt.Type != 3 {
+					continue
+				}
+				raws = append(raws, opt)
+				b, err := ndp.MarshalMessage(&ndp.RouterAdvertisement{Options: []ndp.Option{opt}})
+				require.NoError(t, err)
+				parsed, err := ndp.ParseMessage(b)
+				require.NoError(t, err)
+				require.Len(t, parsed.(*ndp.RouterAdvertisement).Options, 1)
+				pis = append(pis, parsed.(*ndp.RouterAdvertisement).Options[0].(*ndp.PrefixInformation))
+			}
+		}
+		return pis, raws
+	}
+
+	t.Run("Ensure the P flag is set only for the DHCPv6-PD preferred prefix", func(t *testing.T) {
+		var ra fakeRA
+		select {
+		case ra = <-sock.txMulticastCh():
+		case <-time.After(time.Second):
+			require.Fail(t, "timeout waiting for RA")
+		}
+
+		pis, raws := prefixOptions(t, ra.msg)
+		require.Len(t, pis, 2)
+		require.Len(t, raws, 1)
+
+		// L, A, and P flags
+		require.Equal(t, uint8(0xd0), raws[0].Value[1])
+
+		require.Equal(t, &ndp.PrefixInformation{
+			PrefixLength:                   64,
+			OnLink:                         true,
+			AutonomousAddressConfiguration: true,
+			ValidLifetime:                  172800 * time.Second,
+			PreferredLifetime:              86400 * time.Second,
+			Prefix:                         netip.MustParseAddr("fd00:1::"),
+		}, pis[1])
+	})
+
+	t.Run("Ensure the P flag is kept in the final RAs with zero lifetimes", func(t *testing.T) {
+		cancel()
+
+		var last fakeRA
+		timeout := time.After(time.Second * 3)
+	loop:
+		for {
+			select {
+			case ra, ok := <-sock.txMulticastCh():
+				if !ok {
+					break loop
+				}
+				last = ra
+			case <-timeout:
+				require.Fail(t, "timeout waiting for the socket to be closed")
+			}
+		}
+
+		pis, raws := prefixOptions(t, last.msg)
+		require.Len(t, pis, 2)
+		require.Len(t, raws, 1)
+		require.Equal(t, uint8(0xd0), raws[0].Value[1])
+		for _, pi := range pis {
+			require.Equal(t, time.Duration(0), pi.PreferredLifetime)
+			require.Equal(t, 2*time.Hour, pi.ValidLifetime)
+		}
+
+		select {
+		case <-runDone:
+		case <-time.After(time.Second * 2):
+			require.Fail(t, "daemon didn't stop in time")
+		}
+	})
+}
+
+// newTestSENDCredentials generates the RSA private key and the self-signed
+// certificate for it. Returns the key and the PEM-encoded ones.
+func newTestSENDCredentials(t *testing.T) (*rsa.PrivateKey, string, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "router.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})
+
+	return key, string(keyPEM), string(certPEM)
+}
+
+// verifySENDMessage verifies the CGA and the signature of the SEND signed
+// RA and returns the parsed RA
+func verifySENDMessage(t *testing.T, raw fakeRaw, key *rsa.PrivateKey) *ndp.RouterAdvertisement {
+	t.Helper()
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	// Collect the SEND options
+	options := map[byte][]byte{}
+	sigOffset := 0
+	for i := 16; i < len(raw.b); {
+		typ, length := raw.b[i], int(raw.b[i+1])*8
+		require.NotZero(t, length)
+		options[typ] = raw.b[i+2 : i+length]
+		if typ == 12 {
+			sigOffset = i
+			require.Equal(t, len(raw.b), i+length, "RSA Signature option must be the last one")
+		}
+		i += length
+	}
+
+	// CGA option. The source address must be the CGA generated from
+	// the CGA Parameters (RFC3972 Section 5).
+	cga, ok := options[11]
+	require.True(t, ok)
+	params := cga[2 : len(cga)-int(cga[0])]
+	require.Equal(t, []byte{0xfe, 0x80, 0, 0, 0, 0, 0, 0}, params[16:24])
+	require.Equal(t, publicKey, params[25:])
+	require.Equal(t, params[16:24], raw.from.AsSlice()[:8])
+	hash1 := sha1.Sum(params)
+	iid := raw.from.AsSlice()[8:]
+	require.Equal(t, hash1[0]&0x1c, iid[0]&0x1c)
+	require.Equal(t, hash1[1:8], iid[1:])
+
+	// Timestamp option
+	ts, ok := options[13]
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now(), time.Unix(int64(binary.BigEndian.Uint64(ts[6:])>>16), 0), 5*time.Second)
+
+	// RSA Signature option
+	sig, ok := options[12]
+	require.True(t, ok)
+	keyHash := sha1.Sum(publicKey)
+	require.Equal(t, keyHash[:16], sig[2:18])
+	signed := append(append(append(bytes.Clone(sendMessageTypeTag), raw.from.AsSlice()...), raw.to.WithZone("").AsSlice()...), raw.b[:sigOffset]...)
+	hashed := sha1.Sum(signed)
+	require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA1, hashed[:], sig[18:18+key.Size()]))
+
+	msg, err := ndp.ParseMessage(raw.b)
+	require.NoError(t, err)
+
+	return msg.(*ndp.RouterAdvertisement)
+}
+
+func TestDaemonSEND(t *testing.T) {
+	key, keyPEM, certPEM := newTestSENDCredentials(t)
+
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "net0",
+				RAIntervalMilliseconds:         100,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				RouterLifetimeSeconds:          1800,
+				Prefixes: []*PrefixConfig{
+					{
+						Prefix:     "fd00::/64",
+						OnLink:     true,
+						Autonomous: true,
+					},
+				},
+				SEND: &SENDConfig{
+					PrivateKey:   keyPEM,
+					Certificates: []string{certPEM},
+				},
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	// Waits for the raw message sent to the address
+	waitRaw := func(t *testing.T, to netip.Addr) fakeRaw {
+		timeout := time.After(time.Second * 2)
+		for {
+			select {
+			case raw := <-sock.txRawCh():
+				if raw.to == to {
+					return raw
+				}
+			case <-timeout:
+				require.FailNow(t, "timeout waiting for the raw message")
+			}
+		}
+	}
+
+	var src netip.Addr
+
+	t.Run("Ensure the unsolicited RAs are signed", func(t *testing.T) {
+		raw := waitRaw(t, netip.IPv6LinkLocalAllNodes())
+		require.True(t, raw.from.IsLinkLocalUnicast())
+		src = raw.from
+
+		ra := verifySENDMessage(t, raw, key)
+		require.Equal(t, 1800*time.Second, ra.RouterLifetime)
+
+		prefixes := 0
+		for _, option := range ra.Options {
+			switch option.(type) {
+			case *ndp.PrefixInformation:
+				prefixes++
+			case *ndp.Nonce:
+				require.Fail(t, "unsolicited RA must not have the Nonce option")
+			}
+		}
+		require.Equal(t, 1, prefixes)
+
+		// Nothing is sent without the signature
+		require.Empty(t, sock.txMulticastCh())
+
+		require.True(t, sock.isRawOpen())
+	})
+
+	t.Run("Ensure the nonce is echoed back in the solicited RA", func(t *testing.T) {
+		from := netip.MustParseAddr("fe80::1")
+		nonce := ndp.NewNonce()
+		sock.rxCh() <- fakeRS{
+			msg: &ndp.RouterSolicitation{
+				Options: []ndp.Option{
+					&ndp.LinkLayerAddress{Direction: ndp.Source, Addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}},
+					nonce,
+				},
+			},
+			from:     from,
+			hopLimit: 255,
+		}
+
+		raw := waitRaw(t, from)
+		require.Equal(t, src, raw.from)
+		ra := verifySENDMessage(t, raw, key)
+
+		found := false
+		for _, option := range ra.Options {
+			if n, ok := option.(*ndp.Nonce); ok {
+				require.True(t, nonce.Equal(n))
+				found = true
+			}
+		}
+		require.True(t, found)
+	})
+
+	t.Run("Ensure the CPS is answered with the certificate", func(t *testing.T) {
+		block, _ := pem.Decode([]byte(certPEM))
+
+		for _, from := range []netip.Addr{netip.MustParseAddr("fe80::2"), netip.IPv6Unspecified()} {
+			sock.rxCPSCh() <- fakeCPS{
+				msg:  &certPathSolicitation{identifier: 42, component: 65535},
+				from: from,
+			}
+
+			to := from
+			if from.IsUnspecified() {
+				to = netip.IPv6LinkLocalAllNodes()
+			}
+
+			raw := waitRaw(t, to)
+			require.Equal(t, src, raw.from)
+
+			// Type, Code, Identifier, All Components, and Component
+			require.Equal(t, byte(149), raw.b[0])
+			require.Equal(t, byte(0), raw.b[1])
+			require.Equal(t, uint16(42), binary.BigEndian.Uint16(raw.b[4:6]))
+			require.Equal(t, uint16(1), binary.BigEndian.Uint16(raw.b[6:8]))
+			require.Equal(t, uint16(0), binary.BigEndian.Uint16(raw.b[8:10]))
+
+			// Certificate option
+			require.Equal(t, byte(16), raw.b[12])
+			require.Equal(t, len(raw.b)-12, int(raw.b[13])*8)
+			require.Equal(t, byte(1), raw.b[14])
+			require.Equal(t, block.Bytes, raw.b[16:16+len(block.Bytes)])
+		}
+	})
+
+	// Reloads the configuration and waits for the signed RA with the new
+	// configuration. Returns its source address.
+	reloadSigned := func(t *testing.T) netip.Addr {
+		config.Interfaces[0].CurrentHopLimit++
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancelTimeout()
+		require.NoError(t, d.Reload(timeout, config))
+
+		for {
+			raw := waitRaw(t, netip.IPv6LinkLocalAllNodes())
+			ra := verifySENDMessage(t, raw, key)
+			if ra.CurrentHopLimit == uint8(config.Interfaces[0].CurrentHopLimit) {
+				return raw.from
+			}
+		}
+	}
+
+	t.Run("Ensure the random CGA is kept across the reloads", func(t *testing.T) {
+		require.Equal(t, src, reloadSigned(t))
+	})
+
+	t.Run("Ensure the CGA is generated from the configured modifier", func(t *testing.T) {
+		modifier := []byte{
+			0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77,
+			0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+		}
+		config.Interfaces[0].SEND.Modifier = "00112233445566778899aabbccddeeff"
+
+		publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+		addr, _ := generateCGA(publicKey, modifier, linkLocalSubnetPrefix, 0)
+
+		from := reloadSigned(t)
+		require.Equal(t, addr, from)
+		require.NotEqual(t, src, from)
+	})
+
+	t.Run("Ensure the RAs are not signed after disabling SEND", func(t *testing.T) {
+		config.Interfaces[0].SEND = nil
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		err := d.Reload(timeout, config)
+		require.NoError(t, err)
+		cancelTimeout()
+
+		select {
+		case ra := <-sock.txMulticastCh():
+			require.Equal(t, 1800*time.Second, ra.msg.RouterLifetime)
+		case <-time.After(time.Second):
+			require.Fail(t, "timeout waiting for the unsigned RA")
+		}
+	})
+
+	t.Run("Ensure the raw connection is closed after disabling SEND", func(t *testing.T) {
+		require.False(t, sock.isRawOpen())
+
+		// Skip the signed RAs sent before disabling SEND
+		for len(sock.txRawCh()) > 0 {
+			<-sock.txRawCh()
+		}
+
+		// The CPS is not received anymore
+		sock.rxCPSCh() <- fakeCPS{
+			msg:  &certPathSolicitation{identifier: 42, component: 65535},
+			from: netip.MustParseAddr("fe80::2"),
+		}
+		time.Sleep(time.Millisecond * 200)
+		require.Len(t, sock.rxCPS, 1)
+		require.Empty(t, sock.txRawCh())
+	})
+}
+
+func TestDaemonFinalRAs(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                   "net0",
+				RAIntervalMilliseconds: 1000,
+				RouterLifetimeSeconds:  1800,
+				Preference:             "high",
+			},
+			{
+				Name:                   "net1",
+				RAIntervalMilliseconds: 1000,
+				RouterLifetimeSeconds:  1800,
+				ZeroLifetimesOnStop:    true,
+				Prefixes: []*PrefixConfig{
+					{
+						Prefix:                   "fd00::/64",
+						OnLink:                   true,
+						Autonomous:               true,
+						PreferredLifetimeSeconds: ptr.To(86400),
+						ValidLifetimeSeconds:     ptr.To(172800),
+					},
+				},
+				Routes: []*RouteConfig{
+					{
+						Prefix:          "2001:db8::/64",
+						LifetimeSeconds: 100,
+					},
+				},
+				RDNSSes: []*RDNSSConfig{
+					{
+						LifetimeSeconds: 300,
+						Addresses:       []string{"2001:db8::1"},
+					},
+				},
+				PvDs: []*PvDConfig{
+					{
+						FQDN:     "pvd.example.com",
+						RAHeader: true,
+						Routes: []*RouteConfig{
+							{
+								Prefix:          "2001:db8:1::/64",
+								LifetimeSeconds: 100,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0", "net1")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+	devWatcher.update("net1", deviceState{isUp: true, addr: net.HardwareAddr{0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	runDone := make(chan any)
+	go func() {
+		d.Run(ctx)
+		close(runDone)
+	}()
+
+	// Ensures the last RAs before closing the socket are the final RAs
+	// and returns them.
+	finalRAs := func(t *testing.T, sock *fakeSock) []fakeRA {
+		ras := []fakeRA{}
+		timeout := time.After(time.Second * 3)
+	loop:
+		for {
+			select {
+			case ra, ok := <-sock.txMulticastCh():
+				if !ok {
+					break loop
+				}
+				ras = append(ras, ra)
+			case <-timeout:
+				require.Fail(t, "timeout waiting for the socket to be closed")
+			}
+		}
+		require.GreaterOrEqual(t, len(ras), 3)
+		final := ras[len(ras)-3:]
+		for _, ra := range final {
+			require.Equal(t, time.Duration(0), ra.msg.RouterLifetime)
+			require.Equal(t, ndp.Medium, ra.msg.RouterSelectionPreference)
+		}
+		return final
+	}
+
+	var sock0, sock1 *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		var err0, err1 error
+		sock0, err0 = reg.getSock("net0")
+		sock1, err1 = reg.getSock("net1")
+		assert.NoError(ct, err0)
+		assert.NoError(ct, err1)
+	}, time.Second*1, time.Millisecond*10)
+
+	t.Run("Ensure final RAs are sent after removing configuration", func(t *testing.T) {
+		config.Interfaces = config.Interfaces[1:]
+
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+		err := d.Reload(timeout, config)
+		require.NoError(t, err)
+		cancelTimeout()
+
+		for _, ra := range finalRAs(t, sock0) {
+			// Options are untouched
+			require.Len(t, ra.msg.Options, 1)
+		}
+	})
+
+	t.Run("Ensure final RAs with zero lifetimes are sent after stopping the daemon", func(t *testing.T) {
+		cancel()
+
+		for _, ra := range finalRAs(t, sock1) {
+			for _, option := range ra.msg.Options {
+				switch opt := option.(type) {
+				case *ndp.PrefixInformation:
+					require.Equal(t, time.Duration(0), opt.PreferredLifetime)
+					require.Equal(t, 2*time.Hour, opt.ValidLifetime)
+				case *ndp.RouteInformation:
+					require.Equal(t, time.Duration(0), opt.RouteLifetime)
+				case *ndp.RecursiveDNSServer:
+					require.Equal(t, time.Duration(0), opt.Lifetime)
+				case *ndp.RawOption:
+					// The PvD content has changed, so the
+					// sequence number must be bumped
+					_, seq, nested := parsePvDOption(t, opt)
+					require.Equal(t, uint16(1), seq)
+					require.Equal(t, time.Duration(0), nested.RouterLifetime)
+					require.Len(t, nested.Options, 1)
+					require.Equal(t, time.Duration(0), nested.Options[0].(*ndp.RouteInformation).RouteLifetime)
+				}
+			}
+		}
+
+		select {
+		case <-runDone:
+		case <-time.After(time.Second * 2):
+			require.Fail(t, "daemon didn't stop in time")
+		}
+	})
+}
+
+func TestDaemonDeprecation(t *testing.T) {
+	config := &Config{
+		Interfaces: []*InterfaceConfig{
+			{
+				Name:                           "net0",
+				RAIntervalMilliseconds:         100,
+				MinDelayBetweenRAsMilliseconds: ptr.To(0),
+				DeprecationPeriodSeconds:       ptr.To(1),
+				Prefixes: []*PrefixConfig{
+					{
+						Prefix:     "fd00::/64",
+						OnLink:     true,
+						Autonomous: true,
+					},
+					{
+						Prefix:     "fd01::/64",
+						OnLink:     true,
+						Autonomous: true,
+					},
+				},
+				Routes: []*RouteConfig{
+					{
+						Prefix:          "2001:db8::/64",
+						LifetimeSeconds: 100,
+					},
+					{
+						Prefix:          "2001:db8:1::/64",
+						LifetimeSeconds: 100,
+					},
+				},
+				RDNSSes: []*RDNSSConfig{
+					{
+						LifetimeSeconds: 300,
+						Addresses:       []string{"2001:db8::1", "2001:db8::2"},
+					},
+				},
+				DNSSLs: []*DNSSLConfig{
+					{
+						LifetimeSeconds: 300,
+						DomainNames:     []string{"example.com", "example.net"},
+					},
+				},
+				NAT64Prefixes: []*NAT64PrefixConfig{
+					{
+						Prefix: "64:ff9b::/96",
+					},
+				},
+				PvDs: []*PvDConfig{
+					{
+						FQDN: "a.example.com",
+						Prefixes: []*PrefixConfig{
+							{
+								Prefix: "fd10::/64",
+							},
+							{
+								Prefix: "fd11::/64",
+							},
+						},
+						DNSSLs: []*DNSSLConfig{
+							{
+								LifetimeSeconds: 300,
+								DomainNames:     []string{"a.example.com", "b.example.com"},
+							},
+						},
+					},
+					{
+						FQDN: "b.example.com",
+						Routes: []*RouteConfig{
+							{
+								Prefix:          "2001:db8:2::/64",
+								LifetimeSeconds: 100,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	reg := newFakeSockRegistry()
+
+	devWatcher := newFakeDeviceWatcher("net0")
+	devWatcher.update("net0", deviceState{isUp: true, addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}})
+
+	d, err := NewDaemon(
+		config,
+		withSocketConstructor(reg.newSock),
+		withDeviceWatcher(devWatcher),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go d.Run(ctx)
+
+	var sock *fakeSock
+	require.EventuallyWithT(t, func(ct *assert.CollectT) {
+		sock, err = reg.getSock("net0")
+		assert.NoError(ct, err)
+	}, time.Second*1, time.Millisecond*10)
+
+	// Remove one of each
+	config.Interfaces[0].Prefixes = config.Interfaces[0].Prefixes[:1]
+	config.Interfaces[0].Routes = config.Interfaces[0].Routes[:1]
+	config.Interfaces[0].RDNSSes[0].Addresses = config.Interfaces[0].RDNSSes[0].Addresses[:1]
+	config.Interfaces[0].NAT64Prefixes = nil
+	config.Interfaces[0].DNSSLs[0].DomainNames = config.Interfaces[0].DNSSLs[0].DomainNames[:1]
+	config.Interfaces[0].PvDs[0].Prefixes = config.Interfaces[0].PvDs[0].Prefixes[:1]
+	config.Interfaces[0].PvDs[0].DNSSLs[0].DomainNames = config.Interfaces[0].PvDs[0].DNSSLs[0].DomainNames[:1]
+	config.Interfaces[0].PvDs = config.Interfaces[0].PvDs[:1]
+
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second*1)
+	err = d.Reload(timeout, config)
+	require.NoError(t, err)
+	cancelTimeout()
+
+	t.Run("Ensure the removed options are advertised with zero lifetimes", func(t *testing.T) {
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			ra := <-sock.txMulticastCh()
+
+			prefixes := map[netip.Addr]*ndp.PrefixInformation{}
+			routes := map[netip.Addr]*ndp.RouteInformation{}
+			rdnsses := map[netip.Addr]time.Duration{}
+			dnssls := map[string]time.Duration{}
+			nat64prefixes := map[netip.Prefix]*ndp.PREF64{}
+			for _, option := range ra.msg.Options {
+				switch opt := option.(type) {
+				case *ndp.PrefixInformation:
+					prefixes[opt.Prefix] = opt
+				case *ndp.RouteInformation:
+					routes[opt.Prefix] = opt
+				case *ndp.RecursiveDNSServer:
+					for _, addr := range opt.Servers {
+						rdnsses[addr] = opt.Lifetime
+					}
+				case *ndp.DNSSearchList:
+					for _, name := range opt.DomainNames {
+						dnssls[name] = opt.Lifetime
+					}
+				case *ndp.PREF64:
+					nat64prefixes[opt.Prefix] = opt
+				}
+			}
+
+			if !assert.Contains(ct, prefixes, netip.MustParseAddr("fd01::")) {
+				return
+			}
+			// The valid lifetime is capped instead of being zero
+			assert.Equal(ct, 2*time.Hour, prefixes[netip.MustParseAddr("fd01::")].ValidLifetime)
+			assert.Equal(ct, time.Duration(0), prefixes[netip.MustParseAddr("fd01::")].PreferredLifetime)
+			assert.NotEqual(ct, time.Duration(0), prefixes[netip.MustParseAddr("fd00::")].ValidLifetime)
+
+			if !assert.Contains(ct, routes, netip.MustParseAddr("2001:db8:1::")) {
+				return
+			}
+			assert.Equal(ct, time.Duration(0), routes[netip.MustParseAddr("2001:db8:1::")].RouteLifetime)
+			assert.NotEqual(ct, time.Duration(0), routes[netip.MustParseAddr("2001:db8::")].RouteLifetime)
+
+			assert.Equal(ct, time.Duration(0), rdnsses[netip.MustParseAddr("2001:db8::2")])
+			assert.NotEqual(ct, time.Duration(0), rdnsses[netip.MustParseAddr("2001:db8::1")])
+
+			if !assert.Contains(ct, dnssls, "example.net") {
+				return
+			}
+			assert.Equal(ct, time.Duration(0), dnssls["example.net"])
+			assert.NotEqual(ct, time.Duration(0), dnssls["example.com"])
+
+			if !assert.Contains(ct, nat64prefixes, netip.MustParsePrefix("64:ff9b::/96")) {
+				return
+			}
+			assert.Equal(ct, time.Duration(0), nat64prefixes[netip.MustParsePrefix("64:ff9b::/96")].Lifetime)
+		}, time.Second*1, time.Millisecond*10)
+	})
+
+	// pvdOptions returns the PvD options in the RA. The configured PvD
+	// comes first, then the withdrawn one.
+	pvdOptions := func(ra fakeRA) []*ndp.RawOption {
+		options := []*ndp.RawOption{}
+		for _, option := range ra.msg.Options {
+			if raw, ok := option.(*ndp.RawOption); ok && raw.Type == 21 {
+				options = append(options, raw)
+			}
+		}
+		return options
+	}
+
+	var lastSeq uint16
+
+	t.Run("Ensure the removed options nested in PvDs are deprecated within the PvDs", func(t *testing.T) {
+		ra := <-sock.txMulticastCh()
+		pvds := pvdOptions(ra)
+		require.Len(t, pvds, 2)
+
+		// The remaining PvD carries the removed prefix and domain name
+		// with zero lifetimes
+		_, seq, nested := parsePvDOption(t, pvds[0])
+		require.NotZero(t, seq, "sequence number must be bumped")
+		lastSeq = seq
+		prefixes := map[netip.Addr]*ndp.PrefixInformation{}
+		dnssls := map[string]time.Duration{}
+		for _, option := range nested.Options {
+			switch opt := option.(type) {
+			case *ndp.PrefixInformation:
+				prefixes[opt.Prefix] = opt
+			case *ndp.DNSSearchList:
+				for _, name := range opt.DomainNames {
+					dnssls[name] = opt.Lifetime
+				}
+			}
+		}
+		require.Contains(t, prefixes, netip.MustParseAddr("fd11::"))
+		require.Equal(t, time.Duration(0), prefixes[netip.MustParseAddr("fd11::")].PreferredLifetime)
+		require.Equal(t, 2*time.Hour, prefixes[netip.MustParseAddr("fd11::")].ValidLifetime)
+		require.NotEqual(t, time.Duration(0), prefixes[netip.MustParseAddr("fd10::")].PreferredLifetime)
+		require.Equal(t, time.Duration(0), dnssls["b.example.com"])
+		require.NotEqual(t, time.Duration(0), dnssls["a.example.com"])
+
+		// The removed PvD is kept only with the deprecated route
+		_, seq, nested = parsePvDOption(t, pvds[1])
+		require.NotZero(t, seq, "sequence number must be bumped")
+		require.Len(t, nested.Options, 1)
+		route, ok := nested.Options[0].(*ndp.RouteInformation)
+		require.True(t, ok)
+		require.Equal(t, netip.MustParseAddr("2001:db8:2::"), route.Prefix)
+		require.Equal(t, time.Duration(0), route.RouteLifetime)
+	})
+
+	t.Run("Ensure the status reports the deprecated options", func(t *testing.T) {
+		status := d.Status()
+		require.Len(t, status.Interfaces, 1)
+		deprecated := []string{}
+		for _, d := range status.Interfaces[0].DeprecatedOptions {
+			if d.PvD != "" {
+				deprecated = append(deprecated, d.PvD+": "+d.Type+" "+d.Value)
+				continue
+			}
+			deprecated = append(deprecated, d.Type+" "+d.Value)
+		}
+		require.Equal(t, []string{
+			"DNSSL example.net",
+			"NAT64Prefix 64:ff9b::/96",
+			"Prefix fd01::/64",
+			"RDNSS 2001:db8::2",
+			"Route 2001:db8:1::/64",
+			"a.example.com: DNSSL b.example.com",
+			"a.example.com: Prefix fd11::/64",
+			"b.example.com: Route 2001:db8:2::/64",
+		}, deprecated)
+	})
+
+	t.Run("Ensure the deprecated options are removed after the period", func(t *testing.T) {
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			ra := <-sock.txMulticastCh()
+			// SLLA, prefix, route, RDNSS, DNSSL, and PvD
+			if !assert.Len(ct, ra.msg.Options, 6) {
+				return
+			}
+			assert.Empty(ct, d.Status().Interfaces[0].DeprecatedOptions)
+
+			// The content of the PvD has changed again
+			pvds := pvdOptions(ra)
+			if !assert.Len(ct, pvds, 1) {
+				return
+			}
+			_, seq, nested := parsePvDOption(t, pvds[0])
+			assert.Greater(ct, seq, lastSeq)
+			assert.Len(ct, nested.Options, 2)
+		}, time.Second*3, time.Millisecond*10)
+	})
+}
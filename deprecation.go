@@ -0,0 +1,264 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"cmp"
+	"maps"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/mdlayher/ndp"
+)
+
+// Possible types of the deprecated options
+const (
+	deprecatedPrefix      = "Prefix"
+	deprecatedRoute       = "Route"
+	deprecatedRDNSS       = "RDNSS"
+	deprecatedDNSSL       = "DNSSL"
+	deprecatedNAT64Prefix = "NAT64Prefix"
+)
+
+// deprecationKey identifies the advertised item. For RDNSS and DNSSL, each
+// address or domain name is tracked separately since the option may contain
+// multiple of them. The items nested in the PvD option are identified by the
+// FQDN of the PvD as well.
+type deprecationKey struct {
+	pvd   string
+	kind  string
+	value string
+}
+
+// deprecatedOption is the withdrawn item advertised with zero lifetimes
+type deprecatedOption struct {
+	option ndp.Option
+	until  time.Time
+}
+
+// deprecationTracker remembers the items withdrawn from the RA and keeps
+// advertising them with zero lifetimes for a while so that the hosts stop
+// using the stale information immediately (RFC4192, RFC9096). The items
+// nested in the PvD options are deprecated within the same PvD. It is not
+// thread-safe.
+type deprecationTracker struct {
+	// The items advertised with non-zero lifetimes last time
+	advertised map[deprecationKey]ndp.Option
+
+	// The items being deprecated
+	deprecated map[deprecationKey]*deprecatedOption
+
+	// The PvDs advertised last time by FQDN
+	pvds map[string]*PvDConfig
+
+	// The PvDs withdrawn from the RA by FQDN. They are kept advertised
+	// without their own items while any nested item is being deprecated.
+	withdrawnPvDs map[string]*PvDConfig
+}
+
+func newDeprecationTracker() *deprecationTracker {
+	return &deprecationTracker{
+		advertised:    map[deprecationKey]ndp.Option{},
+		deprecated:    map[deprecationKey]*deprecatedOption{},
+		pvds:          map[string]*PvDConfig{},
+		withdrawnPvDs: map[string]*PvDConfig{},
+	}
+}
+
+// update compares the newly advertised options and PvDs with the previous
+// ones and starts deprecating the withdrawn items for the given period. The
+// items advertised again are no longer deprecated.
+func (t *deprecationTracker) update(options []ndp.Option, pvds []*PvDConfig, period time.Duration, now time.Time) {
+	advertised := map[deprecationKey]ndp.Option{}
+	addAdvertisedItems(advertised, "", options)
+
+	current := map[string]*PvDConfig{}
+	for _, pvd := range pvds {
+		addAdvertisedItems(advertised, pvd.FQDN, createPvDNestedOptions(pvd))
+		current[pvd.FQDN] = pvd
+	}
+
+	for key, option := range t.advertised {
+		if _, ok := advertised[key]; ok {
+			continue
+		}
+		if period == 0 {
+			continue
+		}
+		t.deprecated[key] = &deprecatedOption{option: option, until: now.Add(period)}
+	}
+
+	for key := range advertised {
+		delete(t.deprecated, key)
+	}
+
+	// The withdrawn PvD carries only the deprecated items
+	for fqdn, pvd := range t.pvds {
+		if _, ok := current[fqdn]; !ok {
+			withdrawn := *pvd
+			withdrawn.Prefixes = nil
+			withdrawn.Routes = nil
+			withdrawn.RDNSSes = nil
+			withdrawn.DNSSLs = nil
+			t.withdrawnPvDs[fqdn] = &withdrawn
+		}
+	}
+	for fqdn := range current {
+		delete(t.withdrawnPvDs, fqdn)
+	}
+	t.removeEmptyPvDs()
+
+	t.advertised = advertised
+	t.pvds = current
+}
+
+// addAdvertisedItems adds the items in the options to advertised. The
+// option of each item is the copy of the option with zero lifetimes.
+func addAdvertisedItems(advertised map[deprecationKey]ndp.Option, pvd string, options []ndp.Option) {
+	for _, option := range options {
+		switch opt := option.(type) {
+		case *ndp.PrefixInformation:
+			p := netip.PrefixFrom(opt.Prefix, int(opt.PrefixLength))
+			advertised[deprecationKey{pvd: pvd, kind: deprecatedPrefix, value: p.String()}] = deprecatePrefixInformation(opt)
+		case *ndp.RouteInformation:
+			p := netip.PrefixFrom(opt.Prefix, int(opt.PrefixLength))
+			advertised[deprecationKey{pvd: pvd, kind: deprecatedRoute, value: p.String()}] = &ndp.RouteInformation{
+				PrefixLength: opt.PrefixLength,
+				Preference:   opt.Preference,
+				Prefix:       opt.Prefix,
+			}
+		case *ndp.RecursiveDNSServer:
+			for _, addr := range opt.Servers {
+				advertised[deprecationKey{pvd: pvd, kind: deprecatedRDNSS, value: addr.String()}] = &ndp.RecursiveDNSServer{
+					Servers: []netip.Addr{addr},
+				}
+			}
+		case *ndp.DNSSearchList:
+			for _, name := range opt.DomainNames {
+				advertised[deprecationKey{pvd: pvd, kind: deprecatedDNSSL, value: name}] = &ndp.DNSSearchList{
+					DomainNames: []string{name},
+				}
+			}
+		case *ndp.PREF64:
+			advertised[deprecationKey{pvd: pvd, kind: deprecatedNAT64Prefix, value: opt.Prefix.String()}] = &ndp.PREF64{
+				Prefix: opt.Prefix,
+			}
+		}
+	}
+}
+
+// deprecatePrefixInformation returns the copy of the Prefix Information
+// option for the withdrawn prefix. The preferred lifetime is zero. The valid
+// lifetime is capped by minPrefixValidLifetimeUpdate instead since the hosts
+// ignore the shorter one (RFC4862 Section 5.5.3 (e)).
+func deprecatePrefixInformation(opt *ndp.PrefixInformation) *ndp.PrefixInformation {
+	o := *opt
+	o.PreferredLifetime = 0
+	o.ValidLifetime = min(o.ValidLifetime, minPrefixValidLifetimeUpdate)
+	return &o
+}
+
+// expire removes the items whose deprecation period has ended. Returns true
+// when any item expired.
+func (t *deprecationTracker) expire(now time.Time) bool {
+	expired := false
+	for key, d := range t.deprecated {
+		if !now.Before(d.until) {
+			delete(t.deprecated, key)
+			expired = true
+		}
+	}
+	if expired {
+		t.removeEmptyPvDs()
+	}
+	return expired
+}
+
+// removeEmptyPvDs forgets the withdrawn PvDs without any item being
+// deprecated
+func (t *deprecationTracker) removeEmptyPvDs() {
+	inUse := map[string]bool{}
+	for key := range t.deprecated {
+		inUse[key.pvd] = true
+	}
+	for fqdn := range t.withdrawnPvDs {
+		if !inUse[fqdn] {
+			delete(t.withdrawnPvDs, fqdn)
+		}
+	}
+}
+
+// options returns the options with zero lifetimes for the items being
+// deprecated in the given PvD. The empty FQDN means the items outside of
+// any PvD.
+func (t *deprecationTracker) options(pvd string) []ndp.Option {
+	options := []ndp.Option{}
+	rdnss := &ndp.RecursiveDNSServer{}
+	dnssl := &ndp.DNSSearchList{}
+	for _, key := range t.sortedKeys() {
+		if key.pvd != pvd {
+			continue
+		}
+		option := t.deprecated[key].option
+		switch key.kind {
+		case deprecatedRDNSS:
+			// Merge all addresses into a single RDNSS option
+			rdnss.Servers = append(rdnss.Servers, option.(*ndp.RecursiveDNSServer).Servers...)
+		case deprecatedDNSSL:
+			// Merge all domain names into a single DNSSL option
+			dnssl.DomainNames = append(dnssl.DomainNames, option.(*ndp.DNSSearchList).DomainNames...)
+		default:
+			options = append(options, option)
+		}
+	}
+	if len(rdnss.Servers) > 0 {
+		options = append(options, rdnss)
+	}
+	if len(dnssl.DomainNames) > 0 {
+		options = append(options, dnssl)
+	}
+	return options
+}
+
+// pvdsWithdrawn returns the withdrawn PvDs still carrying the deprecated
+// items in the order of FQDN
+func (t *deprecationTracker) pvdsWithdrawn() []*PvDConfig {
+	ret := []*PvDConfig{}
+	for _, fqdn := range slices.Sorted(maps.Keys(t.withdrawnPvDs)) {
+		ret = append(ret, t.withdrawnPvDs[fqdn])
+	}
+	return ret
+}
+
+// status returns the items being deprecated
+func (t *deprecationTracker) status() []*DeprecatedOptionStatus {
+	ret := []*DeprecatedOptionStatus{}
+	for _, key := range t.sortedKeys() {
+		ret = append(ret, &DeprecatedOptionStatus{
+			Type:  key.kind,
+			Value: key.value,
+			PvD:   key.pvd,
+			Until: t.deprecated[key].until.Unix(),
+		})
+	}
+	return ret
+}
+
+func (t *deprecationTracker) sortedKeys() []deprecationKey {
+	keys := []deprecationKey{}
+	for key := range t.deprecated {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b deprecationKey) int {
+		if c := cmp.Compare(a.pvd, b.pvd); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.kind, b.kind); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.value, b.value)
+	})
+	return keys
+}
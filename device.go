@@ -3,18 +3,65 @@ package ra
 import (
 	"context"
 	"net"
+	"net/netip"
+	"slices"
+	"time"
 
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 )
 
 type deviceState struct {
 	isUp             bool
 	v6LLAddrAssigned bool
-	addr             net.HardwareAddr
+
+	// The link-layer address used in the Source Link-Layer Address
+	// option. Nil when the link doesn't use the link-layer address.
+	addr net.HardwareAddr
+
+	// The link MTU. Zero when unknown.
+	mtu int
+
+	// The interface index. Zero when unknown.
+	index int
+
+	// The IPv6 link-local addresses assigned to the device without zone
+	addrs []netip.Addr
+
+	// The IPv6 global and ULA addresses assigned to the device
+	globalAddrs []deviceAddr
+}
+
+// deviceAddr is the address assigned to the device with its lifetimes
+type deviceAddr struct {
+	// The address and the prefix length
+	prefix netip.Prefix
+
+	// The time the address becomes invalid and deprecated. Zero when the
+	// lifetime is infinite.
+	validUntil     time.Time
+	preferredUntil time.Time
+}
+
+// The lifetime of the address which never expires (IFA_F_PERMANENT)
+const infiniteAddrLifetime = 0xffffffff
+
+// addrLifetimeEnd converts the remaining lifetime of the address in seconds
+// to the absolute time
+func addrLifetimeEnd(lifetime int, now time.Time) time.Time {
+	if lifetime == infiniteAddrLifetime {
+		return time.Time{}
+	}
+	return now.Add(time.Duration(lifetime) * time.Second)
 }
 
 type deviceWatcher interface {
 	watch(ctx context.Context, name string) (<-chan deviceState, error)
+
+	// Assigns and removes the link-local address of the device. The
+	// changes are reported by watch as the other address changes.
+	addLinkLocalAddr(name string, addr netip.Addr) error
+	delLinkLocalAddr(name string, addr netip.Addr) error
 }
 
 type netlinkDeviceWatcher struct{}
@@ -25,6 +72,22 @@ func newDeviceWatcher() deviceWatcher {
 	return &netlinkDeviceWatcher{}
 }
 
+func (w *netlinkDeviceWatcher) addLinkLocalAddr(name string, addr netip.Addr) error {
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		return err
+	}
+	return addLinkLocalAddr(link, addr)
+}
+
+func (w *netlinkDeviceWatcher) delLinkLocalAddr(name string, addr netip.Addr) error {
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		return err
+	}
+	return delLinkLocalAddr(link, addr)
+}
+
 func (w *netlinkDeviceWatcher) watch(ctx context.Context, name string) (<-chan deviceState, error) {
 	linkCh := make(chan netlink.LinkUpdate)
 	addrCh := make(chan netlink.AddrUpdate)
@@ -65,6 +128,16 @@ func (w *netlinkDeviceWatcher) watch(ctx context.Context, name string) (<-chan d
 				}
 				currentState.isUp = link.Flags&uint32(net.FlagUp) != 0
 				currentState.addr = link.Attrs().HardwareAddr
+
+				// The point-to-point and NOARP links (e.g. tun,
+				// WireGuard, and GRE) don't use the link-layer
+				// address even if the device has one (e.g. GRE
+				// reports the IPv4 address of the tunnel).
+				if link.Attrs().RawFlags&(unix.IFF_POINTOPOINT|unix.IFF_NOARP) != 0 {
+					currentState.addr = nil
+				}
+				currentState.mtu = link.Attrs().MTU
+				currentState.index = link.Attrs().Index
 				devCh <- currentState
 			case addr := <-addrCh:
 				iface, err := net.InterfaceByIndex(addr.LinkIndex)
@@ -74,14 +147,40 @@ func (w *netlinkDeviceWatcher) watch(ctx context.Context, name string) (<-chan d
 				if iface.Name != name {
 					continue
 				}
-				if !addr.LinkAddress.IP.IsLinkLocalUnicast() {
+				ip, ok := netip.AddrFromSlice(addr.LinkAddress.IP)
+				if !ok || !ip.Is6() || ip.Is4In6() {
+					continue
+				}
+				if ip.IsGlobalUnicast() {
+					// Don't modify the slice already sent
+					globalAddrs := slices.DeleteFunc(slices.Clone(currentState.globalAddrs), func(a deviceAddr) bool {
+						return a.prefix.Addr() == ip
+					})
+					if addr.NewAddr {
+						bits, _ := addr.LinkAddress.Mask.Size()
+						now := time.Now()
+						globalAddrs = append(globalAddrs, deviceAddr{
+							prefix:         netip.PrefixFrom(ip, bits),
+							validUntil:     addrLifetimeEnd(addr.ValidLft, now),
+							preferredUntil: addrLifetimeEnd(addr.PreferedLft, now),
+						})
+					}
+					currentState.globalAddrs = globalAddrs
+					devCh <- currentState
+					continue
+				}
+				if !ip.IsLinkLocalUnicast() {
 					continue
 				}
+				// Don't modify the slice already sent
+				addrs := slices.DeleteFunc(slices.Clone(currentState.addrs), func(a netip.Addr) bool {
+					return a == ip
+				})
 				if addr.NewAddr {
-					currentState.v6LLAddrAssigned = true
-				} else {
-					currentState.v6LLAddrAssigned = false
+					addrs = append(addrs, ip)
 				}
+				currentState.addrs = addrs
+				currentState.v6LLAddrAssigned = len(addrs) > 0
 				devCh <- currentState
 			}
 		}
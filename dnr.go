@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/mdlayher/ndp"
+)
+
+const (
+	// Encrypted DNS option type (RFC9463 Section 6.1)
+	dnrOptionType = 144
+
+	// SvcParamKeys (RFC9460 Section 14.3.2)
+	svcParamKeyALPN = 1
+	svcParamKeyPort = 3
+)
+
+var errDNROptionTooLong = errors.New("DNR option is too long")
+
+// marshalDNROption encodes the DNRConfig into the Encrypted DNS option
+// (RFC9463 Section 6.1).
+func marshalDNROption(c *DNRConfig) (*ndp.RawOption, error) {
+	adn, err := marshalDomainName(c.AuthenticationDomainName)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := []byte{}
+	for _, a := range c.Addresses {
+		addr, err := netip.ParseAddr(a)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr.AsSlice()...)
+	}
+
+	// SvcParams must be sorted by key in strictly increasing order
+	// (RFC9460 Section 2.2).
+	alpn := []byte{}
+	for _, id := range c.ALPNs {
+		alpn = append(alpn, byte(len(id)))
+		alpn = append(alpn, id...)
+	}
+	svcParams := appendSvcParam(nil, svcParamKeyALPN, alpn)
+	if c.Port != 0 {
+		svcParams = appendSvcParam(svcParams, svcParamKeyPort, binary.BigEndian.AppendUint16(nil, uint16(c.Port)))
+	}
+
+	value := binary.BigEndian.AppendUint16(nil, uint16(c.ServicePriority))
+	value = binary.BigEndian.AppendUint32(value, uint32(c.LifetimeSeconds))
+	value = binary.BigEndian.AppendUint16(value, uint16(len(adn)))
+	value = append(value, adn...)
+	value = binary.BigEndian.AppendUint16(value, uint16(len(addrs)))
+	value = append(value, addrs...)
+	value = binary.BigEndian.AppendUint16(value, uint16(len(svcParams)))
+	value = append(value, svcParams...)
+
+	// Pad up to the multiple of 8 bytes including the Type and Length
+	// fields. The padding must be zero (RFC9463 Section 6.1).
+	if r := (len(value) + 2) % 8; r != 0 {
+		value = append(value, make([]byte, 8-r)...)
+	}
+
+	if len(value)+2 > maxRawOptionLength {
+		return nil, errDNROptionTooLong
+	}
+
+	return newRawOption(dnrOptionType, value), nil
+}
+
+// appendSvcParam appends the SvcParam in the wire format (RFC9460 Section
+// 2.2) to b.
+func appendSvcParam(b []byte, key uint16, value []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, key)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(value)))
+	return append(b, value...)
+}
+
+// marshalDomainName encodes the domain name in the uncompressed DNS wire
+// format (RFC1035 Section 3.1).
+func marshalDomainName(name string) ([]byte, error) {
+	b := []byte{}
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return nil, fmt.Errorf("invalid domain name %q", name)
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0), nil
+}
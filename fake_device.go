@@ -1,16 +1,28 @@
 package ra
 
-import "context"
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"sync"
+)
 
 type fakeDeviceWatcher struct {
 	watchers map[string]chan deviceState
+
+	// The link-local addresses assigned by addLinkLocalAddr. The state
+	// isn't updated automatically, so the tests must report the address
+	// with update.
+	linkLocalAddrs     map[string][]netip.Addr
+	linkLocalAddrsLock sync.Mutex
 }
 
 var _ deviceWatcher = &fakeDeviceWatcher{}
 
 func newFakeDeviceWatcher(devs ...string) *fakeDeviceWatcher {
 	fdw := &fakeDeviceWatcher{
-		watchers: make(map[string]chan deviceState),
+		watchers:       make(map[string]chan deviceState),
+		linkLocalAddrs: make(map[string][]netip.Addr),
 	}
 	for _, dev := range devs {
 		fdw.watchers[dev] = make(chan deviceState, 1)
@@ -39,3 +51,29 @@ func (w *fakeDeviceWatcher) watch(ctx context.Context, name string) (<-chan devi
 func (w *fakeDeviceWatcher) update(name string, dev deviceState) {
 	w.watchers[name] <- dev
 }
+
+func (w *fakeDeviceWatcher) addLinkLocalAddr(name string, addr netip.Addr) error {
+	w.linkLocalAddrsLock.Lock()
+	defer w.linkLocalAddrsLock.Unlock()
+	if !slices.Contains(w.linkLocalAddrs[name], addr) {
+		w.linkLocalAddrs[name] = append(w.linkLocalAddrs[name], addr)
+	}
+	return nil
+}
+
+func (w *fakeDeviceWatcher) delLinkLocalAddr(name string, addr netip.Addr) error {
+	w.linkLocalAddrsLock.Lock()
+	defer w.linkLocalAddrsLock.Unlock()
+	w.linkLocalAddrs[name] = slices.DeleteFunc(w.linkLocalAddrs[name], func(a netip.Addr) bool {
+		return a == addr
+	})
+	return nil
+}
+
+// assignedLinkLocalAddrs returns the link-local addresses assigned by
+// addLinkLocalAddr
+func (w *fakeDeviceWatcher) assignedLinkLocalAddrs(name string) []netip.Addr {
+	w.linkLocalAddrsLock.Lock()
+	defer w.linkLocalAddrsLock.Unlock()
+	return slices.Clone(w.linkLocalAddrs[name])
+}
@@ -0,0 +1,85 @@
+package ra
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"sync"
+
+	"github.com/vishvananda/netlink"
+)
+
+type fakeRouteWatcher struct {
+	present  bool
+	watchers []chan bool
+
+	routes        []netlink.Route
+	routeWatchers []*fakeRoutesWatch
+
+	lock sync.Mutex
+}
+
+type fakeRoutesWatch struct {
+	filter *routeFilter
+	ch     chan []netip.Prefix
+}
+
+var _ routeWatcher = &fakeRouteWatcher{}
+
+func newFakeRouteWatcher(present bool) *fakeRouteWatcher {
+	return &fakeRouteWatcher{present: present}
+}
+
+func (w *fakeRouteWatcher) watch(ctx context.Context, _ netip.Prefix, _ int) (<-chan bool, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	ch := make(chan bool, 16)
+	ch <- w.present
+	w.watchers = append(w.watchers, ch)
+
+	return ch, nil
+}
+
+func (w *fakeRouteWatcher) update(present bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.present = present
+	for _, ch := range w.watchers {
+		ch <- present
+	}
+}
+
+func (w *fakeRouteWatcher) watchRoutes(ctx context.Context, filter *routeFilter) (<-chan []netip.Prefix, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	watch := &fakeRoutesWatch{filter: filter, ch: make(chan []netip.Prefix, 16)}
+	watch.ch <- w.filterRoutes(filter)
+	w.routeWatchers = append(w.routeWatchers, watch)
+
+	return watch.ch, nil
+}
+
+// updateRoutes replaces the routes in the fake routing tables
+func (w *fakeRouteWatcher) updateRoutes(routes []netlink.Route) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.routes = routes
+	for _, watch := range w.routeWatchers {
+		watch.ch <- w.filterRoutes(watch.filter)
+	}
+}
+
+func (w *fakeRouteWatcher) filterRoutes(filter *routeFilter) []netip.Prefix {
+	prefixes := []netip.Prefix{}
+	for _, route := range w.routes {
+		if dst, ok := filter.match(&route); ok {
+			prefixes = append(prefixes, dst)
+		}
+	}
+	slices.SortFunc(prefixes, comparePrefix)
+	return slices.Compact(prefixes)
+}
@@ -30,7 +30,9 @@ func (r *fakeSockRegistry) newSock(iface string) (socket, error) {
 	r.regLock.Lock()
 	defer r.regLock.Unlock()
 
-	if _, ok := r.reg[iface]; ok {
+	// Allow re-creating the socket once the previous one is closed (e.g.
+	// after the device goes down and up again).
+	if fs, ok := r.reg[iface]; ok && !fs.isClosed() {
 		return nil, fmt.Errorf("duplicate interface name")
 	}
 
@@ -38,6 +40,8 @@ func (r *fakeSockRegistry) newSock(iface string) (socket, error) {
 		txMulticast: make(chan fakeRA, 128),
 		txLLUnicast: make(chan fakeRA, 128),
 		rx:          make(chan fakeRS, 128),
+		txRaw:       make(chan fakeRaw, 128),
+		rxCPS:       make(chan fakeCPS, 128),
 	}
 	r.reg[iface] = fs
 
@@ -61,17 +65,39 @@ type fakeSock struct {
 	txMulticast chan fakeRA
 	txLLUnicast chan fakeRA
 	rx          chan fakeRS
+	txRaw       chan fakeRaw
+	rxCPS       chan fakeCPS
 	closed      atomic.Bool
+	rawOpen     atomic.Bool
+
+	// Only accessed from the advertiser's main loop
+	srcAddr     netip.Addr
+	virtualAddr netip.Addr
 }
 
 type fakeRA struct {
 	tstamp time.Time
 	msg    *ndp.RouterAdvertisement
+	from   netip.Addr
 	to     netip.Addr
 }
 
 type fakeRS struct {
-	msg  *ndp.RouterSolicitation
+	msg      *ndp.RouterSolicitation
+	from     netip.Addr
+	hopLimit int
+	code     uint8
+}
+
+type fakeRaw struct {
+	tstamp time.Time
+	b      []byte
+	from   netip.Addr
+	to     netip.Addr
+}
+
+type fakeCPS struct {
+	msg  *certPathSolicitation
 	from netip.Addr
 }
 
@@ -89,12 +115,20 @@ func (s *fakeSock) rxCh() chan<- fakeRS {
 	return s.rx
 }
 
+func (s *fakeSock) txRawCh() <-chan fakeRaw {
+	return s.txRaw
+}
+
+func (s *fakeSock) rxCPSCh() chan<- fakeCPS {
+	return s.rxCPS
+}
+
 func (s *fakeSock) hardwareAddr() net.HardwareAddr {
 	return net.HardwareAddr{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
 }
 
 func (s *fakeSock) sendRA(_ context.Context, addr netip.Addr, msg *ndp.RouterAdvertisement) error {
-	ra := fakeRA{tstamp: time.Now(), msg: msg, to: addr}
+	ra := fakeRA{tstamp: time.Now(), msg: msg, from: s.virtualAddr, to: addr}
 	if addr.IsMulticast() {
 		select {
 		case s.txMulticast <- ra:
@@ -119,13 +153,64 @@ func (s *fakeSock) recvRS(ctx context.Context) (*ndp.RouterSolicitation, netip.A
 	case <-ctx.Done():
 		return nil, netip.Addr{}, ctx.Err()
 	case rs := <-s.rx:
+		if err := validateRS(rs.hopLimit, rs.code, rs.msg, rs.from); err != nil {
+			return nil, netip.Addr{}, err
+		}
 		return rs.msg, rs.from, nil
 	}
 }
 
+func (s *fakeSock) openRaw() error {
+	s.rawOpen.Store(true)
+	return nil
+}
+
+func (s *fakeSock) closeRaw() {
+	s.rawOpen.Store(false)
+}
+
+func (s *fakeSock) isRawOpen() bool {
+	return s.rawOpen.Load()
+}
+
+func (s *fakeSock) sendRaw(_ context.Context, addr netip.Addr, b []byte) error {
+	if !s.isRawOpen() {
+		return errRawClosed
+	}
+	select {
+	case s.txRaw <- fakeRaw{tstamp: time.Now(), b: b, from: s.srcAddr, to: addr}:
+		return nil
+	default:
+		return fmt.Errorf("tx raw channel is full")
+	}
+}
+
+func (s *fakeSock) recvCPS(ctx context.Context) (*certPathSolicitation, netip.Addr, error) {
+	if !s.isRawOpen() {
+		return nil, netip.Addr{}, errRawClosed
+	}
+	select {
+	case <-ctx.Done():
+		return nil, netip.Addr{}, ctx.Err()
+	case cps := <-s.rxCPS:
+		return cps.msg, cps.from, nil
+	}
+}
+
+func (s *fakeSock) setSourceAddr(addr netip.Addr) error {
+	s.srcAddr = addr
+	return nil
+}
+
+func (s *fakeSock) setVirtualSourceAddr(addr netip.Addr) {
+	s.virtualAddr = addr
+}
+
 func (s *fakeSock) close() {
 	close(s.txMulticast)
+	close(s.txRaw)
 	close(s.rx)
+	s.closeRaw()
 	s.closed.Store(true)
 }
 
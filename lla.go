@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"net"
+
+	"github.com/mdlayher/ndp"
+)
+
+const (
+	// The length of the Ethernet (EUI-48) address. The only length
+	// mdlayher/ndp can encode into the Link-Layer Address options.
+	ethernetAddrLength = 6
+
+	// The length of the IPoIB link-layer address (RFC4391 Section 9.1)
+	ipoibAddrLength = 20
+)
+
+// createSourceLinkLayerAddressOption creates the Source Link-Layer Address
+// option for the link-layer address of any length. Returns nil when the
+// link doesn't have the link-layer address (e.g. tun, WireGuard, and GRE).
+// mdlayher/ndp only supports the 48-bit address, so the other ones are
+// encoded by ourselves and wrapped with ndp.RawOption.
+func createSourceLinkLayerAddressOption(addr net.HardwareAddr) ndp.Option {
+	switch len(addr) {
+	case 0:
+		return nil
+	case ethernetAddrLength:
+		return &ndp.LinkLayerAddress{
+			Direction: ndp.Source,
+			Addr:      addr,
+		}
+	}
+
+	// The IPoIB link-layer address follows the two reserved bytes (RFC4391
+	// Section 9.1.2). The other addresses follow the Length field and the
+	// option is padded with zeros (RFC4861 Section 4.6.1).
+	value := []byte{}
+	if len(addr) == ipoibAddrLength {
+		value = append(value, 0, 0)
+	}
+	value = append(value, addr...)
+	length := (len(value) + 2 + 7) / 8
+	value = append(value, make([]byte, length*8-len(value)-2)...)
+
+	return &ndp.RawOption{
+		Type:   byte(ndp.Source),
+		Length: uint8(length),
+		Value:  value,
+	}
+}
+
+// isSourceLinkLayerAddressOption returns true when the option is the Source
+// Link-Layer Address option created by createSourceLinkLayerAddressOption
+func isSourceLinkLayerAddressOption(option ndp.Option) bool {
+	switch opt := option.(type) {
+	case *ndp.LinkLayerAddress:
+		return opt.Direction == ndp.Source
+	case *ndp.RawOption:
+		return opt.Type == byte(ndp.Source)
+	}
+	return false
+}
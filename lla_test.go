@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mdlayher/ndp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSourceLinkLayerAddressOption(t *testing.T) {
+	tests := []struct {
+		name   string
+		addr   net.HardwareAddr
+		option ndp.Option
+	}{
+		{
+			name:   "No address",
+			addr:   nil,
+			option: nil,
+		},
+		{
+			name:   "48-bit",
+			addr:   net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66},
+			option: &ndp.LinkLayerAddress{Direction: ndp.Source, Addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}},
+		},
+		{
+			name: "56-bit",
+			addr: net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77},
+			option: &ndp.RawOption{
+				Type:   byte(ndp.Source),
+				Length: 2,
+				Value:  []byte{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0, 0, 0, 0, 0, 0, 0},
+			},
+		},
+		{
+			name: "64-bit",
+			addr: net.HardwareAddr{0x02, 0x11, 0x22, 0xff, 0xfe, 0x33, 0x44, 0x55},
+			option: &ndp.RawOption{
+				Type:   byte(ndp.Source),
+				Length: 2,
+				Value:  []byte{0x02, 0x11, 0x22, 0xff, 0xfe, 0x33, 0x44, 0x55, 0, 0, 0, 0, 0, 0},
+			},
+		},
+		{
+			name: "IPoIB",
+			addr: net.HardwareAddr{
+				0x80, 0x00, 0x00, 0x48, 0xfe, 0x80, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x02, 0xc9, 0x03, 0x00, 0x01, 0x02, 0x03,
+			},
+			option: &ndp.RawOption{
+				Type:   byte(ndp.Source),
+				Length: 3,
+				Value: []byte{
+					0x00, 0x00,
+					0x80, 0x00, 0x00, 0x48, 0xfe, 0x80, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x02, 0xc9, 0x03, 0x00, 0x01, 0x02, 0x03,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.option, createSourceLinkLayerAddressOption(tt.addr))
+		})
+	}
+}
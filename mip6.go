@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"encoding/binary"
+
+	"github.com/mdlayher/ndp"
+)
+
+// Option types defined in RFC6275
+const (
+	advertisementIntervalOptionType = 7
+	homeAgentInformationOptionType  = 8
+)
+
+// marshalAdvertisementIntervalOption encodes the Advertisement Interval
+// option (RFC6275 Section 7.3).
+func marshalAdvertisementIntervalOption(intervalMilliseconds int) *ndp.RawOption {
+	// Reserved
+	value := []byte{0, 0}
+	value = binary.BigEndian.AppendUint32(value, uint32(intervalMilliseconds))
+	return newRawOption(advertisementIntervalOptionType, value)
+}
+
+// marshalHomeAgentInformationOption encodes the Home Agent Information option
+// (RFC6275 Section 7.4).
+func marshalHomeAgentInformationOption(preference, lifetimeSeconds int) *ndp.RawOption {
+	// Reserved
+	value := []byte{0, 0}
+	value = binary.BigEndian.AppendUint16(value, uint16(int16(preference)))
+	value = binary.BigEndian.AppendUint16(value, uint16(lifetimeSeconds))
+	return newRawOption(homeAgentInformationOptionType, value)
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"net/netip"
+
+	"github.com/mdlayher/ndp"
+)
+
+const (
+	// P (DHCPv6-PD Preferred) flag of the Prefix Information option
+	// (RFC9762 Section 4)
+	pioFlagP = 0x10
+)
+
+// marshalPrefixInformationOption encodes the ndp.PrefixInformation with the
+// P flag set. mdlayher/ndp doesn't support the P flag, so we let it encode
+// the option and set the flag on the result wrapped with ndp.RawOption.
+func marshalPrefixInformationOption(pi *ndp.PrefixInformation) (*ndp.RawOption, error) {
+	b, err := ndp.MarshalMessage(&ndp.RouterAdvertisement{
+		Options: []ndp.Option{pi},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip the RA header. The option value starts after the Type and
+	// Length fields and the flags follow the Prefix Length field.
+	b = b[raHeaderLength:]
+	value := b[2:]
+	value[1] |= pioFlagP
+
+	return &ndp.RawOption{
+		Type:   b[0],
+		Length: b[1],
+		Value:  value,
+	}, nil
+}
+
+// encodePIOFlags replaces the Prefix Information options for the prefixes
+// with the DHCPv6PDPreferred flag with the raw options carrying the P flag.
+// Other options are returned as is.
+func encodePIOFlags(options []ndp.Option, prefixes []*PrefixConfig) []ndp.Option {
+	pdPreferred := map[netip.Prefix]bool{}
+	for _, prefix := range prefixes {
+		if prefix.DHCPv6PDPreferred {
+			// At this point, we should have validated the
+			// configuration. If we haven't, it's a bug.
+			pdPreferred[netip.MustParsePrefix(prefix.Prefix)] = true
+		}
+	}
+
+	if len(pdPreferred) == 0 {
+		return options
+	}
+
+	ret := []ndp.Option{}
+	for _, option := range options {
+		if pi, ok := option.(*ndp.PrefixInformation); ok && pdPreferred[netip.PrefixFrom(pi.Prefix, int(pi.PrefixLength))] {
+			raw, err := marshalPrefixInformationOption(pi)
+			if err != nil {
+				panic("BUG (Please report 🙏): Failed to marshal Prefix Information option: " + err.Error())
+			}
+			option = raw
+		}
+		ret = append(ret, option)
+	}
+	return ret
+}
@@ -0,0 +1,298 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv6"
+	"golang.org/x/sys/unix"
+)
+
+// prober performs a single probe of the health check. The probe must give up
+// when the context is done. close releases the resources kept across the
+// probes and is called once the health checker stops.
+type prober interface {
+	probe(ctx context.Context) error
+	close() error
+}
+
+// newProber creates the prober for the health check configuration. ifName is
+// the name of the advertising interface used to reach the link-local target.
+func newProber(config *HealthCheckConfig, ifName string) prober {
+	switch config.Type {
+	case "tcp":
+		return &tcpProber{target: config.Target}
+	case "icmp":
+		// At this point, we should have validated the configuration.
+		// If we haven't, it's a bug.
+		addr := netip.MustParseAddr(config.Target)
+		if addr.IsLinkLocalUnicast() {
+			addr = addr.WithZone(ifName)
+		}
+		return &icmpProber{target: addr}
+	case "exec":
+		return &execProber{command: config.Command}
+	default:
+		// At this point, we should have validated the configuration.
+		// If we haven't, it's a bug.
+		panic("BUG (Please report 🙏): Unknown health check type: " + config.Type)
+	}
+}
+
+// tcpProber succeeds when the TCP connection to the target is established
+type tcpProber struct {
+	target string
+}
+
+func (p *tcpProber) probe(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", p.target)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (p *tcpProber) close() error {
+	return nil
+}
+
+// icmpProber succeeds when the ICMPv6 echo reply is received from the target
+type icmpProber struct {
+	target netip.Addr
+
+	// The raw socket shared by the probes. Opened by the first probe and
+	// opened again after the socket error.
+	conn *icmp.PacketConn
+}
+
+// open opens the raw socket unless it's already open
+func (p *icmpProber) open() error {
+	if p.conn != nil {
+		return nil
+	}
+
+	conn, err := icmp.ListenPacket("ip6:ipv6-icmp", "::")
+	if err != nil {
+		return err
+	}
+
+	// Only the echo replies are interesting
+	var filter ipv6.ICMPFilter
+	filter.SetAll(true)
+	filter.Accept(ipv6.ICMPTypeEchoReply)
+	if err := conn.IPv6PacketConn().SetICMPFilter(&filter); err != nil {
+		conn.Close()
+		return err
+	}
+
+	p.conn = conn
+	return nil
+}
+
+func (p *icmpProber) probe(ctx context.Context) error {
+	if err := p.open(); err != nil {
+		return err
+	}
+
+	err := p.echo(ctx)
+
+	// The timeout is the usual probe failure. Any other error may be
+	// caused by the socket, so open a new one for the next probe.
+	if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		p.close()
+	}
+
+	return err
+}
+
+func (p *icmpProber) close() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+// echo sends the echo request to the target and waits for the reply. The
+// replies to the earlier probes are discarded by the ID and sequence number.
+func (p *icmpProber) echo(ctx context.Context) error {
+	conn := p.conn
+
+	// Clear the deadline of the previous probe if there's no new one
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	// The kernel fills the checksum for the raw ICMPv6 socket
+	echo := &icmp.Echo{ID: rand.IntN(0xffff + 1), Seq: rand.IntN(0xffff + 1), Data: []byte("go-ra")}
+	b, err := (&icmp.Message{Type: ipv6.ICMPTypeEchoRequest, Body: echo}).Marshal(nil)
+	if err != nil {
+		return err
+	}
+
+	dst := &net.IPAddr{IP: p.target.AsSlice(), Zone: p.target.Zone()}
+	if _, err := conn.WriteTo(b, dst); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		addr, ok := netip.AddrFromSlice(from.(*net.IPAddr).IP)
+		if !ok || addr != p.target.WithZone("") {
+			continue
+		}
+
+		msg, err := icmp.ParseMessage(ipv6.ICMPTypeEchoReply.Protocol(), buf[:n])
+		if err != nil || msg.Type != ipv6.ICMPTypeEchoReply {
+			continue
+		}
+
+		reply, ok := msg.Body.(*icmp.Echo)
+		if !ok || reply.ID != echo.ID || reply.Seq != echo.Seq {
+			continue
+		}
+
+		return nil
+	}
+}
+
+// execProber succeeds when the command exits with status 0
+type execProber struct {
+	command []string
+}
+
+// execWaitDelay is how long the probe waits for the output after the
+// command is killed. The process spawned by the command may keep holding
+// the output and block the probe otherwise.
+const execWaitDelay = 100 * time.Millisecond
+
+func (p *execProber) probe(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, p.command[0], p.command[1:]...)
+
+	// Run the command in its own process group and kill the whole group
+	// on timeout, so that the spawned processes don't outlive the probe.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return unix.Kill(-cmd.Process.Pid, unix.SIGKILL)
+	}
+	cmd.WaitDelay = execWaitDelay
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
+
+func (p *execProber) close() error {
+	return nil
+}
+
+// healthChecker runs the probes periodically and decides the health state
+// with the hysteresis. The router becomes unhealthy after Fall consecutive
+// failures and becomes healthy again after Rise consecutive successes.
+type healthChecker struct {
+	config *HealthCheckConfig
+	prober prober
+
+	// Called with the result of every probe. Must be safe to call from
+	// the other goroutine.
+	report func(*HealthCheckStatus)
+}
+
+func newHealthChecker(config *HealthCheckConfig, ifName string, report func(*HealthCheckStatus)) *healthChecker {
+	return &healthChecker{
+		config: config,
+		prober: newProber(config, ifName),
+		report: report,
+	}
+}
+
+// run starts probing in the background. The router is considered healthy at
+// first. The returned channel reports the changes of the health state until
+// the context is cancelled.
+func (c *healthChecker) run(ctx context.Context) <-chan bool {
+	healthyCh := make(chan bool, 1)
+
+	go func() {
+		status := &HealthCheckStatus{State: Healthy}
+		c.report(status)
+
+		ticker := time.NewTicker(time.Duration(c.config.IntervalMilliseconds) * time.Millisecond)
+		defer ticker.Stop()
+
+		// The probes run only in this goroutine, so the prober can be
+		// closed safely here.
+		defer c.prober.close()
+
+		for {
+			healthy := status.State == Healthy
+
+			probeCtx, cancel := context.WithTimeout(ctx, time.Duration(c.config.TimeoutMilliseconds)*time.Millisecond)
+			err := c.prober.probe(probeCtx)
+			cancel()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			status = status.deepCopy()
+			status.LastProbe = time.Now().Unix()
+			if err == nil {
+				status.Message = ""
+				status.ConsecutiveSuccesses++
+				status.ConsecutiveFailures = 0
+				if !healthy && status.ConsecutiveSuccesses >= c.config.Rise {
+					status.State = Healthy
+				}
+			} else {
+				status.Message = err.Error()
+				status.ConsecutiveSuccesses = 0
+				status.ConsecutiveFailures++
+				if healthy && status.ConsecutiveFailures >= c.config.Fall {
+					status.State = Unhealthy
+				}
+			}
+			c.report(status)
+
+			if healthy != (status.State == Healthy) {
+				select {
+				case healthyCh <- !healthy:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return healthyCh
+}
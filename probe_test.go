@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecProber(t *testing.T) {
+	t.Run("Ensure the probe passes when the command exits with 0", func(t *testing.T) {
+		p := &execProber{command: []string{"true"}}
+		require.NoError(t, p.probe(context.Background()))
+	})
+
+	t.Run("Ensure the probe fails with the output of the command", func(t *testing.T) {
+		p := &execProber{command: []string{"sh", "-c", "echo unhealthy; exit 1"}}
+		require.ErrorContains(t, p.probe(context.Background()), "unhealthy")
+	})
+
+	t.Run("Ensure the probe times out while the spawned process holds the output", func(t *testing.T) {
+		p := &execProber{command: []string{"sh", "-c", "sleep 10 & sleep 10"}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+
+		start := time.Now()
+		require.Error(t, p.probe(ctx))
+		require.Less(t, time.Since(start), time.Second*1)
+	})
+}
+
+func TestICMPProber(t *testing.T) {
+	p := &icmpProber{target: netip.MustParseAddr("::1")}
+	t.Cleanup(func() { p.close() })
+
+	if err := p.open(); err != nil {
+		t.Skipf("Raw ICMPv6 socket is not available: %v", err)
+	}
+	conn := p.conn
+
+	probe := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+		defer cancel()
+		return p.probe(ctx)
+	}
+
+	t.Run("Ensure the socket is shared by the probes", func(t *testing.T) {
+		require.NoError(t, probe())
+		require.NoError(t, probe())
+		require.Same(t, conn, p.conn)
+	})
+
+	t.Run("Ensure the socket is closed by close", func(t *testing.T) {
+		require.NoError(t, p.close())
+		require.Nil(t, p.conn)
+
+		// The next probe opens a new socket
+		require.NoError(t, probe())
+		require.NotNil(t, p.conn)
+		require.NotSame(t, conn, p.conn)
+	})
+}
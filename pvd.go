@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net/netip"
+
+	"github.com/mdlayher/ndp"
+)
+
+const (
+	// PvD option type (RFC8801 Section 3.1)
+	pvdOptionType = 21
+
+	// Flags of the PvD option
+	pvdFlagH = 0x80
+	pvdFlagL = 0x40
+	pvdFlagR = 0x20
+
+	// The length of the RA header including the ICMP header
+	raHeaderLength = 16
+)
+
+var errPvDOptionTooLong = errors.New("PvD option is too long")
+
+// marshalPvDOption encodes the PvDConfig into the PvD option (RFC8801 Section
+// 3.1) with the given sequence number. The header is included in the option
+// when the R flag is set. nested is the options nested in the PvD, usually
+// created by createPvDNestedOptions. The P flag is encoded for the prefixes
+// configured with DHCPv6PDPreferred.
+func marshalPvDOption(c *PvDConfig, seq uint16, header *ndp.RouterAdvertisement, nested []ndp.Option) (*ndp.RawOption, error) {
+	fqdn, err := marshalDomainName(c.FQDN)
+	if err != nil {
+		return nil, err
+	}
+
+	// Marshal the nested options with the RA header. The checksum is left
+	// zero as the RFC requires.
+	msg := *header
+	msg.Options = encodePIOFlags(nested, c.Prefixes)
+	b, err := ndp.MarshalMessage(&msg)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := byte(0)
+	if c.HTTP {
+		flags |= pvdFlagH
+	}
+	if c.Legacy {
+		flags |= pvdFlagL
+	}
+	if c.RAHeader {
+		flags |= pvdFlagR
+	}
+
+	value := []byte{flags, byte(c.Delay) & 0x0f}
+	value = binary.BigEndian.AppendUint16(value, seq)
+	value = append(value, fqdn...)
+
+	// Pad up to the multiple of 8 bytes including the Type and Length
+	// fields, so that the following RA header and options are aligned.
+	if r := (len(value) + 2) % 8; r != 0 {
+		value = append(value, make([]byte, 8-r)...)
+	}
+
+	if c.RAHeader {
+		value = append(value, b...)
+	} else {
+		value = append(value, b[raHeaderLength:]...)
+	}
+
+	if len(value)+2 > maxRawOptionLength {
+		return nil, errPvDOptionTooLong
+	}
+
+	return newRawOption(pvdOptionType, value), nil
+}
+
+func createPvDNestedOptions(c *PvDConfig) []ndp.Option {
+	options := []ndp.Option{}
+	options = append(options, createPrefixOptions(c.Prefixes)...)
+	options = append(options, createRouteOptions(c.Routes)...)
+	options = append(options, createRDNSSOptions(c.RDNSSes, netip.Addr{})...)
+	options = append(options, createDNSSLOptions(c.DNSSLs)...)
+	return options
+}
+
+// isMarshalable returns true when the nested configurations can be
+// converted into the options. This is used by the validation to skip the
+// length check for the invalid configurations which are reported by the
+// other validations.
+func (c *PvDConfig) isMarshalable() bool {
+	for _, prefix := range c.Prefixes {
+		if prefix == nil || prefix.ValidLifetimeSeconds == nil || prefix.PreferredLifetimeSeconds == nil {
+			return false
+		}
+		if _, err := netip.ParsePrefix(prefix.Prefix); err != nil {
+			return false
+		}
+	}
+	for _, route := range c.Routes {
+		if route == nil {
+			return false
+		}
+		if _, err := netip.ParsePrefix(route.Prefix); err != nil {
+			return false
+		}
+	}
+	for _, rdnss := range c.RDNSSes {
+		if rdnss == nil {
+			return false
+		}
+		for _, addr := range rdnss.Addresses {
+			if _, err := netip.ParseAddr(addr); err != nil {
+				return false
+			}
+		}
+	}
+	for _, dnssl := range c.DNSSLs {
+		if dnssl == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// pvdSequence is the sequence number of the PvD option
+type pvdSequence struct {
+	// The sequence number in the configuration
+	configured int
+
+	// The option marshalled with zero sequence number
+	content []byte
+
+	// The sequence number currently advertised
+	current uint16
+}
+
+// pvdSequenceTracker manages the sequence numbers of the PvD options. The
+// sequence number is bumped whenever the content of the PvD option changes
+// (RFC8801 Section 3.1). It is not thread-safe.
+type pvdSequenceTracker struct {
+	sequences map[string]*pvdSequence
+}
+
+func newPvDSequenceTracker() *pvdSequenceTracker {
+	return &pvdSequenceTracker{
+		sequences: map[string]*pvdSequence{},
+	}
+}
+
+// update compares the PvD options with the previous ones and bumps the
+// sequence numbers of the changed ones. When the sequence number in the
+// configuration is changed, it is used as is. marshal encodes the PvD
+// option as it's advertised with zero sequence number.
+func (t *pvdSequenceTracker) update(pvds []*PvDConfig, marshal func(*PvDConfig) *ndp.RawOption) {
+	sequences := map[string]*pvdSequence{}
+
+	for _, pvd := range pvds {
+		option := marshal(pvd)
+
+		seq := &pvdSequence{
+			configured: pvd.SequenceNumber,
+			content:    option.Value,
+			current:    uint16(pvd.SequenceNumber),
+		}
+
+		if old, ok := t.sequences[pvd.FQDN]; ok && old.configured == pvd.SequenceNumber {
+			seq.current = old.current
+			if !bytes.Equal(old.content, seq.content) {
+				seq.current++
+			}
+		}
+
+		sequences[pvd.FQDN] = seq
+	}
+
+	t.sequences = sequences
+}
+
+// sequence returns the sequence number of the PvD option
+func (t *pvdSequenceTracker) sequence(fqdn string) uint16 {
+	if seq, ok := t.sequences[fqdn]; ok {
+		return seq.current
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"time"
+)
+
+// tokenBucket is a simple token bucket rate limiter. It is not thread-safe.
+type tokenBucket struct {
+	// Tokens added per second. Zero means unlimited.
+	rate float64
+
+	// Maximum number of tokens
+	burst float64
+
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now,
+	}
+}
+
+// setLimit changes the rate and the burst. The current tokens are kept
+// (capped by the new burst), so that changing the limit doesn't refill the
+// bucket. The bucket being unlimited so far starts full.
+func (b *tokenBucket) setLimit(rate, burst int, now time.Time) {
+	b.refill(now)
+	if b.rate == 0 {
+		b.tokens = float64(burst)
+	}
+	b.rate = float64(rate)
+	b.burst = float64(burst)
+	b.tokens = min(b.burst, b.tokens)
+}
+
+// refill adds the tokens accumulated since the last refill
+func (b *tokenBucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// allow consumes a token and returns true if available. Otherwise, returns
+// false.
+func (b *tokenBucket) allow(now time.Time) bool {
+	if b.rate == 0 {
+		return true
+	}
+
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
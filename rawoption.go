@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/mdlayher/ndp"
+)
+
+// The maximum length of the option ndp.RawOption can marshal. The Length
+// field is multiplied in uint8, so 31 * 8 is the limit.
+const maxRawOptionLength = 248
+
+// The option types the daemon advertises by itself, or that never belong to
+// RAs. They can't be configured as the raw options.
+var nativeOptionTypes = map[int]bool{
+	1:                               true, // Source Link-Layer Address
+	2:                               true, // Target Link-Layer Address (not for RAs)
+	3:                               true, // Prefix Information
+	5:                               true, // MTU
+	advertisementIntervalOptionType: true,
+	homeAgentInformationOptionType:  true,
+	cgaOptionType:                   true,
+	rsaSignatureOptionType:          true,
+	timestampOptionType:             true,
+	14:                              true, // Nonce
+	pvdOptionType:                   true,
+	24:                              true, // Route Information
+	25:                              true, // RDNSS
+	26:                              true, // RA Flags Extension
+	31:                              true, // DNSSL
+	37:                              true, // Captive-Portal
+	38:                              true, // PREF64
+	dnrOptionType:                   true,
+}
+
+// newRawOption wraps the encoded option value with ndp.RawOption.
+// mdlayher/ndp doesn't support some of the options we advertise (e.g. DNR,
+// PvD, and the Mobile IPv6 options), so we encode them by ourselves and
+// marshal them through this. The value must be padded, so that the option
+// including the Type and Length fields is the multiple of 8 bytes.
+func newRawOption(typ uint8, value []byte) *ndp.RawOption {
+	return &ndp.RawOption{
+		Type:   typ,
+		Length: uint8((len(value) + 2) / 8),
+		Value:  value,
+	}
+}
+
+// value decodes the payload of the raw option
+func (c *RawOptionConfig) value() ([]byte, error) {
+	if c.Base64 != "" {
+		return base64.StdEncoding.DecodeString(c.Base64)
+	}
+	return hex.DecodeString(c.Hex)
+}
+
+func createRawOptions(raws []*RawOptionConfig) []ndp.Option {
+	options := []ndp.Option{}
+	for _, raw := range raws {
+		value, err := raw.value()
+		if err != nil {
+			// At this point, we should have validated the
+			// configuration. If we haven't, it's a bug.
+			panic("BUG (Please report 🙏): Failed to decode raw option: " + err.Error())
+		}
+		options = append(options, newRawOption(uint8(raw.Type), value))
+	}
+	return options
+}
@@ -0,0 +1,501 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+type routeWatcher interface {
+	// watch reports whether the unicast route to the prefix exists in
+	// the routing table. The returned channel already has the current
+	// state when watch returns, and the following changes are reported
+	// until the context is cancelled.
+	watch(ctx context.Context, prefix netip.Prefix, table int) (<-chan bool, error)
+
+	// watchRoutes reports the sorted destinations of the IPv6 unicast
+	// routes matching the filter. The returned channel already has the
+	// current routes when watchRoutes returns, and the following changes
+	// are reported until the context is cancelled. The intermediate
+	// changes may be skipped when the receiver is slow.
+	watchRoutes(ctx context.Context, filter *routeFilter) (<-chan []netip.Prefix, error)
+}
+
+// routeFilter selects the routes advertised by the RouteSource
+type routeFilter struct {
+	table int
+
+	// Empty matches all protocols
+	protocols []netlink.RouteProtocol
+
+	// The destinations must be within one of them. Empty matches all
+	// destinations.
+	prefixes []netip.Prefix
+
+	// The routes through this interface are on the link we advertise to.
+	// Advertising them is pointless, so they are excluded.
+	linkIndex int
+}
+
+// newRouteFilter creates the route filter from the RouteSource configuration
+func newRouteFilter(config *RouteSourceConfig, linkIndex int) *routeFilter {
+	filter := &routeFilter{
+		table:     config.Table,
+		protocols: []netlink.RouteProtocol{},
+		prefixes:  []netip.Prefix{},
+		linkIndex: linkIndex,
+	}
+	for _, name := range config.Protocols {
+		// At this point, we should have validated the
+		// configuration. If we haven't, it's a bug.
+		protocol, ok := parseRouteProtocol(name)
+		if !ok {
+			panic("BUG (Please report 🙏): Unknown route protocol: " + name)
+		}
+		filter.protocols = append(filter.protocols, protocol)
+	}
+	for _, prefix := range config.Prefixes {
+		// At this point, we should have validated the
+		// configuration. If we haven't, it's a bug.
+		filter.prefixes = append(filter.prefixes, netip.MustParsePrefix(prefix).Masked())
+	}
+	return filter
+}
+
+// match returns the destination of the route when the route matches the
+// filter
+func (f *routeFilter) match(route *netlink.Route) (netip.Prefix, bool) {
+	if route.Table != f.table || route.Type != unix.RTN_UNICAST {
+		return netip.Prefix{}, false
+	}
+
+	dst, ok := routeDestination(route)
+	if !ok || !dst.Addr().Is6() || dst.Addr().Is4In6() {
+		return netip.Prefix{}, false
+	}
+
+	// The link-local and multicast routes are never advertised
+	if dst.Addr().IsLinkLocalUnicast() || dst.Addr().IsMulticast() {
+		return netip.Prefix{}, false
+	}
+
+	if len(f.protocols) > 0 && !slices.Contains(f.protocols, route.Protocol) {
+		return netip.Prefix{}, false
+	}
+
+	if len(f.prefixes) > 0 && !slices.ContainsFunc(f.prefixes, func(p netip.Prefix) bool {
+		return p.Bits() <= dst.Bits() && p.Contains(dst.Addr())
+	}) {
+		return netip.Prefix{}, false
+	}
+
+	if f.linkIndex != 0 {
+		if route.LinkIndex == f.linkIndex {
+			return netip.Prefix{}, false
+		}
+		for _, nh := range route.MultiPath {
+			if nh.LinkIndex == f.linkIndex {
+				return netip.Prefix{}, false
+			}
+		}
+	}
+
+	return dst, true
+}
+
+// The well-known route protocols (rtnetlink(7) and /etc/iproute2/rt_protos)
+var routeProtocols = map[string]netlink.RouteProtocol{
+	"kernel": unix.RTPROT_KERNEL,
+	"boot":   unix.RTPROT_BOOT,
+	"static": unix.RTPROT_STATIC,
+	"ra":     unix.RTPROT_RA,
+	"dhcp":   unix.RTPROT_DHCP,
+	"zebra":  unix.RTPROT_ZEBRA,
+	"bird":   unix.RTPROT_BIRD,
+	"babel":  unix.RTPROT_BABEL,
+	"bgp":    unix.RTPROT_BGP,
+	"isis":   unix.RTPROT_ISIS,
+	"ospf":   unix.RTPROT_OSPF,
+	"rip":    unix.RTPROT_RIP,
+	"eigrp":  unix.RTPROT_EIGRP,
+}
+
+// parseRouteProtocol parses the well-known route protocol name or the
+// protocol number
+func parseRouteProtocol(s string) (netlink.RouteProtocol, bool) {
+	if protocol, ok := routeProtocols[s]; ok {
+		return protocol, true
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, false
+	}
+	return netlink.RouteProtocol(n), true
+}
+
+// Interval to list the routes again. The route notifications may be lost
+// when the socket buffer overflows.
+const routeResyncInterval = time.Minute
+
+type netlinkRouteWatcher struct {
+	// The netlink operations. Replaced with the fake ones in the tests.
+	subscribeRoutes func(ch chan<- netlink.RouteUpdate, done <-chan struct{}) error
+	subscribeLinks  func(ch chan<- netlink.LinkUpdate, done <-chan struct{}) error
+	listRoutes      func(family, table int) ([]netlink.Route, error)
+}
+
+var _ routeWatcher = &netlinkRouteWatcher{}
+
+func newRouteWatcher() routeWatcher {
+	return &netlinkRouteWatcher{
+		subscribeRoutes: func(ch chan<- netlink.RouteUpdate, done <-chan struct{}) error {
+			return netlink.RouteSubscribeWithOptions(ch, done, netlink.RouteSubscribeOptions{
+				ErrorCallback: func(err error) {},
+			})
+		},
+		subscribeLinks: func(ch chan<- netlink.LinkUpdate, done <-chan struct{}) error {
+			return netlink.LinkSubscribeWithOptions(ch, done, netlink.LinkSubscribeOptions{
+				ErrorCallback: func(err error) {},
+			})
+		},
+		listRoutes: func(family, table int) ([]netlink.Route, error) {
+			return netlink.RouteListFiltered(family, &netlink.Route{Table: table}, netlink.RT_FILTER_TABLE)
+		},
+	}
+}
+
+func (w *netlinkRouteWatcher) watch(ctx context.Context, prefix netip.Prefix, table int) (<-chan bool, error) {
+	family := netlink.FAMILY_V6
+	if prefix.Addr().Is4() {
+		family = netlink.FAMILY_V4
+	}
+
+	// There may be multiple matching routes with the different metrics
+	// or next hops
+	set := newRouteSet(func(route *netlink.Route) (netip.Prefix, bool) {
+		return prefix, routeMatches(route, prefix, table)
+	})
+
+	presentCh := make(chan bool, 1)
+	present, sent := false, false
+
+	err := w.syncRouteSet(ctx, family, table, set, func() {
+		if sent && present == set.present() {
+			return
+		}
+		present, sent = set.present(), true
+		select {
+		case presentCh <- present:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return presentCh, nil
+}
+
+// watchRoutes watches the routes in the same way as watch
+func (w *netlinkRouteWatcher) watchRoutes(ctx context.Context, filter *routeFilter) (<-chan []netip.Prefix, error) {
+	set := newRouteSet(filter.match)
+
+	prefixesCh := make(chan []netip.Prefix, 1)
+	var current []netip.Prefix
+	sent := false
+
+	err := w.syncRouteSet(ctx, netlink.FAMILY_V6, filter.table, set, func() {
+		prefixes := set.destinations()
+		if sent && slices.Equal(current, prefixes) {
+			return
+		}
+		current, sent = prefixes, true
+
+		// Replace the change not received yet. We're the only
+		// sender, so the send never blocks.
+		select {
+		case <-prefixesCh:
+		default:
+		}
+		prefixesCh <- current
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return prefixesCh, nil
+}
+
+// syncRouteSet keeps the route set in sync with the routing table until the
+// context is cancelled. The routes are listed again when any link goes up or
+// down, since the kernel flushes the IPv4 routes through the link going down
+// without RTM_DELROUTE, and marks the IPv6 routes linkdown silently. They are
+// also listed periodically to recover from the lost notifications. netlink
+// closes the channel when the subscription fails (e.g. the socket buffer
+// overflows). In that case, we subscribe again and list the routes, or retry
+// it periodically. changed is called after the initial listing before
+// syncRouteSet returns, and then after each update from the background
+// goroutine.
+func (w *netlinkRouteWatcher) syncRouteSet(ctx context.Context, family, table int, set *routeSet, changed func()) error {
+	subscribeRoutes := func() (chan netlink.RouteUpdate, error) {
+		ch := make(chan netlink.RouteUpdate)
+		if err := w.subscribeRoutes(ch, ctx.Done()); err != nil {
+			return nil, err
+		}
+		return ch, nil
+	}
+
+	subscribeLinks := func() (chan netlink.LinkUpdate, error) {
+		ch := make(chan netlink.LinkUpdate)
+		if err := w.subscribeLinks(ch, ctx.Done()); err != nil {
+			return nil, err
+		}
+		return ch, nil
+	}
+
+	list := func() error {
+		routes, err := w.listRoutes(family, table)
+		if err != nil {
+			return err
+		}
+		set.reset(routes)
+		return nil
+	}
+
+	// Subscribe before listing the existing routes, so that we don't
+	// miss the changes in between. The same route may be seen twice,
+	// but it's fine since the routes are tracked by the key.
+	routeCh, err := subscribeRoutes()
+	if err != nil {
+		return err
+	}
+
+	linkCh, err := subscribeLinks()
+	if err != nil {
+		return err
+	}
+
+	if err := list(); err != nil {
+		return err
+	}
+	changed()
+
+	go func() {
+		ticker := time.NewTicker(routeResyncInterval)
+		defer ticker.Stop()
+
+		links := linkStates{}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case update, ok := <-routeCh:
+				if ok {
+					set.update(&update)
+					break
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				// Stays nil until the retry succeeds.
+				// Receiving from the nil channel blocks
+				// forever.
+				routeCh, _ = subscribeRoutes()
+				if err := list(); err != nil {
+					continue
+				}
+			case update, ok := <-linkCh:
+				if ok {
+					if !links.update(&update) {
+						continue
+					}
+				} else {
+					if ctx.Err() != nil {
+						return
+					}
+					linkCh, _ = subscribeLinks()
+				}
+				if err := list(); err != nil {
+					continue
+				}
+			case <-ticker.C:
+				if routeCh == nil {
+					routeCh, _ = subscribeRoutes()
+				}
+				if linkCh == nil {
+					linkCh, _ = subscribeLinks()
+				}
+				if err := list(); err != nil {
+					continue
+				}
+			}
+			changed()
+		}
+	}()
+
+	return nil
+}
+
+// routeSet tracks the usable routes matching the filter. The routes whose
+// next hops are all down are treated as absent. It is not thread-safe.
+type routeSet struct {
+	// Returns the destination of the route when it matches the filter
+	match func(*netlink.Route) (netip.Prefix, bool)
+
+	// The destinations of the routes by routeKey
+	routes map[string]netip.Prefix
+}
+
+func newRouteSet(match func(*netlink.Route) (netip.Prefix, bool)) *routeSet {
+	return &routeSet{
+		match:  match,
+		routes: map[string]netip.Prefix{},
+	}
+}
+
+// update applies the route notification
+func (s *routeSet) update(update *netlink.RouteUpdate) {
+	dst, ok := s.match(&update.Route)
+	if !ok {
+		return
+	}
+	key := routeKey(&update.Route)
+	if update.Type == unix.RTM_NEWROUTE && routeUsable(&update.Route) {
+		s.routes[key] = dst
+	} else {
+		delete(s.routes, key)
+	}
+}
+
+// reset replaces the routes with the listed ones
+func (s *routeSet) reset(routes []netlink.Route) {
+	s.routes = map[string]netip.Prefix{}
+	for _, route := range routes {
+		if dst, ok := s.match(&route); ok && routeUsable(&route) {
+			s.routes[routeKey(&route)] = dst
+		}
+	}
+}
+
+// present returns true when any route exists
+func (s *routeSet) present() bool {
+	return len(s.routes) > 0
+}
+
+// destinations returns the unique destinations of the routes in order
+func (s *routeSet) destinations() []netip.Prefix {
+	return sortedDestinations(s.routes)
+}
+
+// routeUsable returns false when all next hops of the route are down (e.g.
+// the link is down and the route is marked linkdown)
+func routeUsable(route *netlink.Route) bool {
+	const down = unix.RTNH_F_LINKDOWN | unix.RTNH_F_DEAD
+	if len(route.MultiPath) == 0 {
+		return route.Flags&down == 0
+	}
+	return slices.ContainsFunc(route.MultiPath, func(nh *netlink.NexthopInfo) bool {
+		return nh.Flags&down == 0
+	})
+}
+
+// linkStates remembers whether each link is up, so that the routes are
+// listed again only when it changes
+type linkStates map[int32]bool
+
+// update records the link state in the notification. Returns true when the
+// link has appeared, disappeared, or gone up or down.
+func (l linkStates) update(update *netlink.LinkUpdate) bool {
+	index := update.Index
+	if update.Header.Type == unix.RTM_DELLINK {
+		delete(l, index)
+		return true
+	}
+	up := update.Flags&unix.IFF_UP != 0 && update.Flags&unix.IFF_LOWER_UP != 0
+	if old, ok := l[index]; ok && old == up {
+		return false
+	}
+	l[index] = up
+	return true
+}
+
+// sortedDestinations returns the unique destinations of the routes in order
+func sortedDestinations(routes map[string]netip.Prefix) []netip.Prefix {
+	prefixes := []netip.Prefix{}
+	for _, prefix := range routes {
+		prefixes = append(prefixes, prefix)
+	}
+	slices.SortFunc(prefixes, comparePrefix)
+	return slices.Compact(prefixes)
+}
+
+// comparePrefix orders the prefixes by the address and then the length
+func comparePrefix(a, b netip.Prefix) int {
+	if c := a.Addr().Compare(b.Addr()); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Bits(), b.Bits())
+}
+
+// routeMatches returns true when the route is the unicast route to the
+// prefix in the table
+func routeMatches(route *netlink.Route, prefix netip.Prefix, table int) bool {
+	if route.Table != table || route.Type != unix.RTN_UNICAST {
+		return false
+	}
+	dst, ok := routeDestination(route)
+	return ok && dst == prefix
+}
+
+// routeDestination returns the destination prefix of the route
+func routeDestination(route *netlink.Route) (netip.Prefix, bool) {
+	// The default route may not have the destination
+	dst := route.Dst
+	if dst == nil {
+		if route.Family == netlink.FAMILY_V4 {
+			dst = &net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)}
+		} else {
+			dst = &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}
+		}
+	}
+
+	addr, ok := netip.AddrFromSlice(dst.IP)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	bits, size := dst.Mask.Size()
+
+	// The IPv4 address may be in the 16-byte form
+	if size == 32 {
+		addr = addr.Unmap()
+	}
+
+	return netip.PrefixFrom(addr, bits), true
+}
+
+// routeKey returns the key identifying the route within the table. The
+// flags are excluded, so that the route marked linkdown replaces the same
+// route without the mark.
+func routeKey(route *netlink.Route) string {
+	r := *route
+	r.Flags = 0
+	r.MultiPath = nil
+	for _, nh := range route.MultiPath {
+		n := *nh
+		n.Flags = 0
+		r.MultiPath = append(r.MultiPath, &n)
+	}
+	return fmt.Sprintf("%s Priority: %d", r.String(), r.Priority)
+}
@@ -0,0 +1,346 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestRouteSet(t *testing.T) {
+	prefix := netip.MustParsePrefix("0.0.0.0/0")
+	newSet := func() *routeSet {
+		return newRouteSet(func(route *netlink.Route) (netip.Prefix, bool) {
+			return prefix, routeMatches(route, prefix, unix.RT_TABLE_MAIN)
+		})
+	}
+
+	defaultRoute := func(linkIndex int, flags int) netlink.Route {
+		return netlink.Route{
+			Family:    netlink.FAMILY_V4,
+			LinkIndex: linkIndex,
+			Gw:        net.ParseIP("192.0.2.1"),
+			Table:     unix.RT_TABLE_MAIN,
+			Type:      unix.RTN_UNICAST,
+			Flags:     flags,
+		}
+	}
+
+	update := func(typ uint16, route netlink.Route) *netlink.RouteUpdate {
+		return &netlink.RouteUpdate{Type: typ, Route: route}
+	}
+
+	t.Run("Link down", func(t *testing.T) {
+		set := newSet()
+
+		set.update(update(unix.RTM_NEWROUTE, defaultRoute(1, 0)))
+		require.True(t, set.present())
+
+		// The kernel marks the route linkdown without RTM_DELROUTE
+		set.update(update(unix.RTM_NEWROUTE, defaultRoute(1, unix.RTNH_F_LINKDOWN)))
+		require.False(t, set.present())
+
+		set.update(update(unix.RTM_NEWROUTE, defaultRoute(1, 0)))
+		require.True(t, set.present())
+
+		set.update(update(unix.RTM_NEWROUTE, defaultRoute(1, unix.RTNH_F_DEAD)))
+		require.False(t, set.present())
+	})
+
+	t.Run("Delete the route marked linkdown", func(t *testing.T) {
+		set := newSet()
+
+		set.update(update(unix.RTM_NEWROUTE, defaultRoute(1, 0)))
+		require.True(t, set.present())
+
+		set.update(update(unix.RTM_DELROUTE, defaultRoute(1, unix.RTNH_F_LINKDOWN)))
+		require.False(t, set.present())
+	})
+
+	t.Run("Flushed without notification", func(t *testing.T) {
+		set := newSet()
+
+		set.reset([]netlink.Route{defaultRoute(1, 0), defaultRoute(2, 0)})
+		require.True(t, set.present())
+
+		// The link 1 went down and its route was flushed
+		set.reset([]netlink.Route{defaultRoute(2, 0)})
+		require.True(t, set.present())
+
+		// The link 2 went down, but its route remains with linkdown
+		set.reset([]netlink.Route{defaultRoute(2, unix.RTNH_F_LINKDOWN)})
+		require.False(t, set.present())
+	})
+
+	t.Run("Multipath", func(t *testing.T) {
+		set := newSet()
+
+		route := defaultRoute(0, 0)
+		route.Gw = nil
+		route.MultiPath = []*netlink.NexthopInfo{
+			{LinkIndex: 1, Gw: net.ParseIP("192.0.2.1")},
+			{LinkIndex: 2, Gw: net.ParseIP("192.0.2.2")},
+		}
+		set.update(update(unix.RTM_NEWROUTE, route))
+		require.True(t, set.present())
+
+		// One of the next hops is still up
+		route.MultiPath[0].Flags = unix.RTNH_F_LINKDOWN
+		set.update(update(unix.RTM_NEWROUTE, route))
+		require.True(t, set.present())
+
+		route.MultiPath[1].Flags = unix.RTNH_F_LINKDOWN | unix.RTNH_F_DEAD
+		set.update(update(unix.RTM_NEWROUTE, route))
+		require.False(t, set.present())
+	})
+}
+
+func TestNetlinkRouteWatcherResubscribe(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		routeChs []chan<- netlink.RouteUpdate
+		linkChs  []chan<- netlink.LinkUpdate
+		routes   []netlink.Route
+		lists    int
+	)
+
+	w := &netlinkRouteWatcher{
+		subscribeRoutes: func(ch chan<- netlink.RouteUpdate, done <-chan struct{}) error {
+			lock.Lock()
+			defer lock.Unlock()
+			routeChs = append(routeChs, ch)
+			return nil
+		},
+		subscribeLinks: func(ch chan<- netlink.LinkUpdate, done <-chan struct{}) error {
+			lock.Lock()
+			defer lock.Unlock()
+			linkChs = append(linkChs, ch)
+			return nil
+		},
+		listRoutes: func(family, table int) ([]netlink.Route, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			lists++
+			return routes, nil
+		},
+	}
+
+	setRoutes := func(r ...netlink.Route) {
+		lock.Lock()
+		defer lock.Unlock()
+		routes = r
+	}
+
+	subscriptions := func() (int, int, int) {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(routeChs), len(linkChs), lists
+	}
+
+	defaultRoute := netlink.Route{
+		Family:    netlink.FAMILY_V6,
+		LinkIndex: 1,
+		Gw:        net.ParseIP("fe80::1"),
+		Table:     unix.RT_TABLE_MAIN,
+		Type:      unix.RTN_UNICAST,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	presentCh, err := w.watch(ctx, netip.MustParsePrefix("::/0"), unix.RT_TABLE_MAIN)
+	require.NoError(t, err)
+	require.False(t, <-presentCh)
+
+	waitPresent := func(t *testing.T, present bool) {
+		select {
+		case p := <-presentCh:
+			require.Equal(t, present, p)
+		case <-time.After(time.Second):
+			require.Fail(t, "route change is not notified")
+		}
+	}
+
+	t.Run("Ensure the routes are subscribed again when the route subscription fails", func(t *testing.T) {
+		// The route is added while the subscription is broken
+		setRoutes(defaultRoute)
+		lock.Lock()
+		close(routeChs[0])
+		lock.Unlock()
+
+		waitPresent(t, true)
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			routeSubs, linkSubs, _ := subscriptions()
+			assert.Equal(ct, 2, routeSubs)
+			assert.Equal(ct, 1, linkSubs)
+		}, time.Second, time.Millisecond*10)
+
+		// The closed channel is not read anymore
+		_, _, lists := subscriptions()
+		time.Sleep(time.Millisecond * 100)
+		_, _, listsAfter := subscriptions()
+		require.Equal(t, lists, listsAfter)
+	})
+
+	t.Run("Ensure the links are subscribed again when the link subscription fails", func(t *testing.T) {
+		// The link went down while the subscription is broken
+		setRoutes()
+		lock.Lock()
+		close(linkChs[0])
+		lock.Unlock()
+
+		waitPresent(t, false)
+
+		require.EventuallyWithT(t, func(ct *assert.CollectT) {
+			routeSubs, linkSubs, _ := subscriptions()
+			assert.Equal(ct, 2, routeSubs)
+			assert.Equal(ct, 2, linkSubs)
+		}, time.Second, time.Millisecond*10)
+
+		_, _, lists := subscriptions()
+		time.Sleep(time.Millisecond * 100)
+		_, _, listsAfter := subscriptions()
+		require.Equal(t, lists, listsAfter)
+	})
+
+	t.Run("Ensure the new subscriptions are used", func(t *testing.T) {
+		lock.Lock()
+		routeCh := routeChs[1]
+		lock.Unlock()
+
+		routeCh <- netlink.RouteUpdate{Type: unix.RTM_NEWROUTE, Route: defaultRoute}
+		waitPresent(t, true)
+	})
+}
+
+func TestLinkStates(t *testing.T) {
+	linkUpdate := func(typ uint16, index int32, flags uint32) *netlink.LinkUpdate {
+		update := &netlink.LinkUpdate{}
+		update.Header.Type = typ
+		update.Index = index
+		update.Flags = flags
+		return update
+	}
+
+	const up = unix.IFF_UP | unix.IFF_LOWER_UP
+
+	links := linkStates{}
+
+	// The first notification of the link
+	require.True(t, links.update(linkUpdate(unix.RTM_NEWLINK, 1, up)))
+
+	// Other attributes changed
+	require.False(t, links.update(linkUpdate(unix.RTM_NEWLINK, 1, up|unix.IFF_PROMISC)))
+
+	// Carrier lost
+	require.True(t, links.update(linkUpdate(unix.RTM_NEWLINK, 1, unix.IFF_UP)))
+	require.False(t, links.update(linkUpdate(unix.RTM_NEWLINK, 1, 0)))
+
+	// Carrier back
+	require.True(t, links.update(linkUpdate(unix.RTM_NEWLINK, 1, up)))
+
+	require.True(t, links.update(linkUpdate(unix.RTM_DELLINK, 1, 0)))
+}
+
+func TestRouteFilterMatch(t *testing.T) {
+	filter := newRouteFilter(&RouteSourceConfig{
+		Table:     254,
+		Protocols: []string{"bgp", "static"},
+		Prefixes:  []string{"2001:db8::/32"},
+	}, 2)
+
+	route := func(dst string, protocol netlink.RouteProtocol, table, linkIndex int) *netlink.Route {
+		_, ipnet, err := net.ParseCIDR(dst)
+		require.NoError(t, err)
+		return &netlink.Route{
+			Family:    netlink.FAMILY_V6,
+			Type:      unix.RTN_UNICAST,
+			Dst:       ipnet,
+			Protocol:  protocol,
+			Table:     table,
+			LinkIndex: linkIndex,
+		}
+	}
+
+	multipath := route("2001:db8:1::/48", unix.RTPROT_BGP, 254, 0)
+	multipath.MultiPath = []*netlink.NexthopInfo{{LinkIndex: 3}, {LinkIndex: 2}}
+
+	unreachable := route("2001:db8:1::/48", unix.RTPROT_BGP, 254, 3)
+	unreachable.Type = unix.RTN_UNREACHABLE
+
+	tests := []struct {
+		name     string
+		route    *netlink.Route
+		expected string
+	}{
+		{
+			name:     "Match",
+			route:    route("2001:db8:1::/48", unix.RTPROT_BGP, 254, 3),
+			expected: "2001:db8:1::/48",
+		},
+		{
+			name:     "Another protocol",
+			route:    route("2001:db8:1::/48", unix.RTPROT_STATIC, 254, 3),
+			expected: "2001:db8:1::/48",
+		},
+		{
+			name:  "Protocol mismatch",
+			route: route("2001:db8:1::/48", unix.RTPROT_KERNEL, 254, 3),
+		},
+		{
+			name:  "Table mismatch",
+			route: route("2001:db8:1::/48", unix.RTPROT_BGP, 100, 3),
+		},
+		{
+			name:  "Out of the prefixes",
+			route: route("2001:db9::/48", unix.RTPROT_BGP, 254, 3),
+		},
+		{
+			name:  "Shorter than the prefix",
+			route: route("2001:db8::/31", unix.RTPROT_BGP, 254, 3),
+		},
+		{
+			name:  "Through the advertising interface",
+			route: route("2001:db8:1::/48", unix.RTPROT_BGP, 254, 2),
+		},
+		{
+			name:  "Multipath through the advertising interface",
+			route: multipath,
+		},
+		{
+			name:  "Link-local",
+			route: route("fe80::/64", unix.RTPROT_BGP, 254, 3),
+		},
+		{
+			name:  "Not unicast",
+			route: unreachable,
+		},
+		{
+			name:  "IPv4",
+			route: route("192.0.2.0/24", unix.RTPROT_BGP, 254, 3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst, ok := filter.match(tt.route)
+			if tt.expected == "" {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, netip.MustParsePrefix(tt.expected), dst)
+		})
+	}
+}
@@ -0,0 +1,345 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/mdlayher/ndp"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	// SEND option types (RFC3971 Section 5 and 6.4.3)
+	cgaOptionType          = 11
+	rsaSignatureOptionType = 12
+	timestampOptionType    = 13
+	certificateOptionType  = 16
+
+	// X.509v3 Certificate in the Certificate option (RFC3971 Section
+	// 6.4.3)
+	certTypeX509 = 1
+
+	// The Component of the CPS soliciting all certificates (RFC3971
+	// Section 6.4.1)
+	cpsAllComponents = 65535
+
+	// The range of the RSA key size we support. The upper bound keeps
+	// the CGA and RSA Signature options within the maximum option length.
+	minSENDKeyBits = 1024
+	maxSENDKeyBits = 4096
+
+	// The maximum length of the option. The Length field is 8 bits in
+	// units of 8 bytes.
+	maxOptionLength = 255 * 8
+)
+
+// The CGA Message Type tag for SEND (RFC3971 Section 5.2)
+var sendMessageTypeTag = []byte{
+	0x08, 0x6f, 0xca, 0x5e, 0x10, 0xb2, 0x00, 0xc9,
+	0x9c, 0x8c, 0xe0, 0x01, 0x64, 0x27, 0x7c, 0x08,
+}
+
+// The subnet prefix of the link-local CGA
+var linkLocalSubnetPrefix = [8]byte{0xfe, 0x80}
+
+// Errors returned by recvCPS when the received CPS is invalid and dropped
+var errCPSMalformed = errors.New("malformed CPS")
+
+// parseRSAPrivateKey parses the PEM-encoded RSA private key in PKCS #1 or
+// PKCS #8 format.
+func parseRSAPrivateKey(s string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("not an RSA private key")
+		}
+		return rsaKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// parseCertificate parses the PEM-encoded X.509 certificate. The
+// certificate must fit in the Certificate option.
+func parseCertificate(s string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(cert.Raw)+4 > maxOptionLength {
+		return nil, errors.New("certificate is too long")
+	}
+	return cert, nil
+}
+
+// The length of the CGA modifier (RFC3972 Section 3)
+const cgaModifierLength = 16
+
+// newCGAModifier returns the random initial modifier of the CGA (RFC3972
+// Section 4)
+func newCGAModifier() []byte {
+	modifier := make([]byte, cgaModifierLength)
+	// Never returns an error (see crypto/rand.Read)
+	rand.Read(modifier)
+	return modifier
+}
+
+// parseCGAModifier parses the hex-encoded CGA modifier
+func parseCGAModifier(s string) ([]byte, error) {
+	modifier, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(modifier) != cgaModifierLength {
+		return nil, fmt.Errorf("modifier must be %d bytes", cgaModifierLength)
+	}
+	return modifier, nil
+}
+
+// generateCGA generates the CGA and its CGA Parameters (RFC3972 Section 4)
+// in the subnet starting from the initial modifier. The collision count is
+// always zero since we don't run the Duplicate Address Detection for the
+// address.
+func generateCGA(publicKey, initialModifier []byte, subnetPrefix [8]byte, sec int) (netip.Addr, []byte) {
+	modifier := slices.Clone(initialModifier)
+
+	// Find the modifier which makes the leftmost 16 * Sec bits of Hash2
+	// zero
+	for {
+		hash2 := sha1.Sum(slices.Concat(modifier, make([]byte, 9), publicKey))
+		if !slices.ContainsFunc(hash2[:2*sec], func(b byte) bool { return b != 0 }) {
+			break
+		}
+		for i := len(modifier) - 1; i >= 0; i-- {
+			modifier[i]++
+			if modifier[i] != 0 {
+				break
+			}
+		}
+	}
+
+	params := slices.Concat(modifier, subnetPrefix[:], []byte{0}, publicKey)
+
+	// The interface identifier is the leftmost 64 bits of Hash1 with Sec
+	// in the leftmost three bits and zero u and g bits
+	hash1 := sha1.Sum(params)
+	iid := hash1[:8]
+	iid[0] = (iid[0] & 0x1f) | byte(sec)<<5
+	iid[0] &^= 0x03
+
+	return netip.AddrFrom16([16]byte(slices.Concat(subnetPrefix[:], iid))), params
+}
+
+// appendOption appends the NDP option padded with zeros up to the multiple
+// of 8 bytes to b.
+func appendOption(b []byte, typ byte, value []byte) []byte {
+	length := (len(value) + 2 + 7) / 8
+	b = append(b, typ, byte(length))
+	b = append(b, value...)
+	return append(b, make([]byte, length*8-len(value)-2)...)
+}
+
+// sendSigner signs the RAs with the SEND options (RFC3971) and answers the
+// CPSes. It is immutable once created.
+type sendSigner struct {
+	key *rsa.PrivateKey
+
+	// The leftmost 128 bits of the SHA-1 hash of the public key
+	keyHash []byte
+
+	// The encoded CGA option
+	cgaOption []byte
+
+	// The link-local CGA which must be used as the source address
+	addr netip.Addr
+
+	// The DER-encoded certificates. The first one is the router's.
+	certificates [][]byte
+}
+
+// newSENDSigner creates the signer. The CGA is generated from the modifier
+// in the configuration, or from the given random one when it's not
+// configured.
+func newSENDSigner(c *SENDConfig, randomModifier []byte) (*sendSigner, error) {
+	key, err := parseRSAPrivateKey(c.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// The public key in the CGA Parameters is the DER-encoded
+	// SubjectPublicKeyInfo (RFC3972 Section 3)
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	certificates := [][]byte{}
+	for _, c := range c.Certificates {
+		cert, err := parseCertificate(c)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, cert.Raw)
+	}
+
+	modifier := randomModifier
+	if c.Modifier != "" {
+		modifier, err = parseCGAModifier(c.Modifier)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	addr, params := generateCGA(publicKey, modifier, linkLocalSubnetPrefix, c.Sec)
+
+	// CGA option (RFC3971 Section 5.1)
+	pad := (8 - (len(params)+4)%8) % 8
+	cgaOption := appendOption(nil, cgaOptionType, slices.Concat([]byte{byte(pad), 0}, params))
+
+	keyHash := sha1.Sum(publicKey)
+
+	return &sendSigner{
+		key:          key,
+		keyHash:      keyHash[:16],
+		cgaOption:    cgaOption,
+		addr:         addr,
+		certificates: certificates,
+	}, nil
+}
+
+// sign marshals the RA sent to dst with the CGA, Timestamp, Nonce, and RSA
+// Signature options (RFC3971 Section 5). The Nonce option is included only
+// when the nonce is not nil, that is, the RA responds to the RS with the
+// Nonce option.
+func (s *sendSigner) sign(msg *ndp.RouterAdvertisement, dst netip.Addr, nonce *ndp.Nonce, now time.Time) ([]byte, error) {
+	m := *msg
+	m.Options = slices.Clone(msg.Options)
+	if nonce != nil {
+		m.Options = append(m.Options, nonce)
+	}
+
+	b, err := ndp.MarshalMessage(&m)
+	if err != nil {
+		return nil, err
+	}
+
+	b = append(b, s.cgaOption...)
+
+	// Timestamp option (RFC3971 Section 5.3.1). The timestamp is the
+	// 48-bit seconds and the 16-bit fraction (1/64K seconds) since epoch.
+	ts := uint64(now.Unix())<<16 | uint64(now.Nanosecond())*65536/uint64(time.Second)
+	b = appendOption(b, timestampOptionType, binary.BigEndian.AppendUint64(make([]byte, 6), ts))
+
+	// The signature covers the message up to this point (RFC3971 Section
+	// 5.2). The checksum is left zero since the kernel calculates it
+	// after the signature is added.
+	hashed := sha1.Sum(slices.Concat(sendMessageTypeTag, s.addr.AsSlice(), dst.WithZone("").AsSlice(), b))
+	sig, err := rsa.SignPKCS1v15(nil, s.key, crypto.SHA1, hashed[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// RSA Signature option (RFC3971 Section 5.2). This must be the last
+	// option.
+	return appendOption(b, rsaSignatureOptionType, slices.Concat([]byte{0, 0}, s.keyHash, sig)), nil
+}
+
+// overhead returns the length of the options sign adds to the RA
+func (s *sendSigner) overhead(nonce *ndp.Nonce) (int, error) {
+	// CGA, Timestamp, and RSA Signature options
+	n := len(s.cgaOption) + 16 + (4+len(s.keyHash)+s.key.Size()+7)/8*8
+	if nonce != nil {
+		l, err := optionsLength([]ndp.Option{nonce})
+		if err != nil {
+			return 0, err
+		}
+		n += l
+	}
+	return n, nil
+}
+
+// certPathSolicitation is the Certification Path Solicitation message
+// (RFC3971 Section 6.4.1)
+type certPathSolicitation struct {
+	identifier uint16
+	component  uint16
+}
+
+// parseCPS parses the CPS. The Trust Anchor options are ignored since we
+// advertise the only certification path anyway.
+func parseCPS(b []byte) (*certPathSolicitation, error) {
+	if len(b) < 8 || ipv6.ICMPType(b[0]) != ipv6.ICMPTypeCertificationPathSolicitation {
+		return nil, errCPSMalformed
+	}
+	if b[1] != 0 {
+		return nil, fmt.Errorf("%w: invalid ICMP code %d", errCPSMalformed, b[1])
+	}
+	return &certPathSolicitation{
+		identifier: binary.BigEndian.Uint16(b[4:6]),
+		component:  binary.BigEndian.Uint16(b[6:8]),
+	}, nil
+}
+
+// certPathAdvertisements returns the Certification Path Advertisement
+// messages (RFC3971 Section 6.4.2) answering the CPS. Each message carries
+// a single certificate. The certificates are sent from the trust anchor
+// side, so the router's certificate is the component 0 sent last.
+func (s *sendSigner) certPathAdvertisements(cps *certPathSolicitation) [][]byte {
+	msgs := [][]byte{}
+	for i := len(s.certificates) - 1; i >= 0; i-- {
+		if cps.component != cpsAllComponents && int(cps.component) != i {
+			continue
+		}
+		b := []byte{byte(ipv6.ICMPTypeCertificationPathAdvertisement), 0, 0, 0}
+		b = binary.BigEndian.AppendUint16(b, cps.identifier)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(s.certificates)))
+		b = binary.BigEndian.AppendUint16(b, uint16(i))
+		b = append(b, 0, 0)
+		b = appendOption(b, certificateOptionType, slices.Concat([]byte{certTypeX509, 0}, s.certificates[i]))
+		msgs = append(msgs, b)
+	}
+	return msgs
+}
+
+// nonceOption returns the Nonce option of the RS. Returns nil if the option
+// doesn't exist.
+func nonceOption(rs *ndp.RouterSolicitation) *ndp.Nonce {
+	for _, option := range rs.Options {
+		if nonce, ok := option.(*ndp.Nonce); ok {
+			return nonce
+		}
+	}
+	return nil
+}
@@ -5,20 +5,55 @@ package ra
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
 	"net/netip"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/mdlayher/ndp"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/net/icmp"
 	"golang.org/x/net/ipv6"
+	"golang.org/x/sys/unix"
 )
 
+// Errors returned by recvRS when the received RS is invalid and dropped
+var (
+	errRSMalformed       = errors.New("malformed RS")
+	errRSInvalidHopLimit = errors.New("RS with invalid hop limit")
+	errRSInvalidCode     = errors.New("RS with invalid ICMP code")
+	errRSInvalidSLLA     = errors.New("RS from unspecified address with Source Link-Layer Address option")
+)
+
+// Error returned by sendRaw and recvCPS while the raw connection is closed
+var errRawClosed = errors.New("raw connection is not open")
+
 // socket is a raw socket for sending RA and receiving RS
 type socket interface {
 	hardwareAddr() net.HardwareAddr
 	sendRA(ctx context.Context, dst netip.Addr, msg *ndp.RouterAdvertisement) error
 	recvRS(ctx context.Context) (*ndp.RouterSolicitation, netip.Addr, error)
+
+	// For the messages mdlayher/ndp can't handle (SEND signed RA, CPS,
+	// and CPA). The raw connection is only opened by openRaw while SEND
+	// is enabled, so that the links without SEND don't depend on it.
+	// closeRaw must not be called while recvCPS is running. The raw
+	// messages are sent from the address set by setSourceAddr.
+	openRaw() error
+	closeRaw()
+	sendRaw(ctx context.Context, dst netip.Addr, b []byte) error
+	recvCPS(ctx context.Context) (*certPathSolicitation, netip.Addr, error)
+	setSourceAddr(addr netip.Addr) error
+
+	// Sends all messages from the address already assigned to the
+	// interface instead of the one chosen by the kernel. Used for the
+	// virtual router. If the address is invalid, the kernel's choice is
+	// used again.
+	setVirtualSourceAddr(addr netip.Addr)
+
 	close()
 }
 
@@ -28,6 +63,20 @@ type socketCtor func(string) (socket, error)
 type sock struct {
 	conn  *ndp.Conn
 	iface *net.Interface
+	addr  netip.Addr
+
+	// The raw ICMPv6 connection for sendRaw and recvCPS. Nil while it's
+	// not open.
+	raw *ipv6.PacketConn
+
+	// The source address of the raw messages assigned to the interface by
+	// setSourceAddr. Invalid when the link-local address of the kernel is
+	// used.
+	srcAddr netip.Addr
+
+	// The source address of all messages set by setVirtualSourceAddr.
+	// Invalid when not set.
+	virtualAddr netip.Addr
 }
 
 var _ socket = &sock{}
@@ -37,11 +86,69 @@ func newSocket(ifaceName string) (socket, error) {
 	if err != nil {
 		return nil, err
 	}
-	conn, _, err := ndp.Listen(iface, ndp.LinkLocal)
+	conn, addr, err := ndp.Listen(iface, ndp.LinkLocal)
 	if err != nil {
 		return nil, err
 	}
-	return &sock{conn: conn, iface: iface}, nil
+	// We need the hop limit to validate the RS
+	if err := conn.SetControlMessage(ipv6.FlagHopLimit, true); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &sock{conn: conn, iface: iface, addr: addr}, nil
+}
+
+func (s *sock) openRaw() error {
+	if s.raw != nil {
+		return nil
+	}
+	raw, err := listenRaw()
+	if err != nil {
+		return err
+	}
+	s.raw = raw
+	return nil
+}
+
+func (s *sock) closeRaw() {
+	if s.raw == nil {
+		return
+	}
+	s.raw.Close()
+	s.raw = nil
+}
+
+// listenRaw opens the raw ICMPv6 connection receiving CPSes. It isn't bound
+// to any address, so that it can receive the CPS sent to the address
+// assigned by setSourceAddr.
+func listenRaw() (*ipv6.PacketConn, error) {
+	ic, err := icmp.ListenPacket("ip6:ipv6-icmp", "::")
+	if err != nil {
+		return nil, err
+	}
+
+	pc := ic.IPv6PacketConn()
+
+	var filter ipv6.ICMPFilter
+	filter.SetAll(true)
+	filter.Accept(ipv6.ICMPTypeCertificationPathSolicitation)
+
+	// Hop limit is always 255 (RFC3971 Section 6.4.1 and 6.4.2) and the
+	// checksum is calculated by the kernel
+	for _, fn := range []func() error{
+		func() error { return pc.SetHopLimit(ndp.HopLimit) },
+		func() error { return pc.SetMulticastHopLimit(ndp.HopLimit) },
+		func() error { return pc.SetChecksum(true, 2) },
+		func() error { return pc.SetICMPFilter(&filter) },
+		func() error { return pc.SetControlMessage(ipv6.FlagHopLimit|ipv6.FlagInterface, true) },
+	} {
+		if err := fn(); err != nil {
+			pc.Close()
+			return nil, err
+		}
+	}
+
+	return pc, nil
 }
 
 func (s *sock) hardwareAddr() net.HardwareAddr {
@@ -58,7 +165,15 @@ func (s *sock) sendRA(ctx context.Context, addr netip.Addr, msg *ndp.RouterAdver
 		// Write to the raw socket shouldn't take long. 2 seconds is long
 		// enough time that indicates something wrong happening.
 		s.conn.SetWriteDeadline(time.Now().Add(time.Second * 2))
-		err = s.conn.WriteTo(msg, nil, addr)
+		var cm *ipv6.ControlMessage
+		if s.virtualAddr.IsValid() {
+			cm = &ipv6.ControlMessage{
+				HopLimit: ndp.HopLimit,
+				Src:      s.virtualAddr.AsSlice(),
+				IfIndex:  s.iface.Index,
+			}
+		}
+		err = s.conn.WriteTo(msg, cm, addr)
 	}()
 
 	select {
@@ -72,7 +187,7 @@ func (s *sock) sendRA(ctx context.Context, addr netip.Addr, msg *ndp.RouterAdver
 
 func (s *sock) recvRS(ctx context.Context) (*ndp.RouterSolicitation, netip.Addr, error) {
 	var (
-		m    ndp.Message
+		rs   *ndp.RouterSolicitation
 		from netip.Addr
 		err  error
 	)
@@ -81,12 +196,21 @@ func (s *sock) recvRS(ctx context.Context) (*ndp.RouterSolicitation, netip.Addr,
 
 	go func() {
 		defer close(ch)
+
+		b := make([]byte, s.iface.MTU)
+
 		for {
 			// Set read deadline to avoid blocking forever. If there's any way
 			// to cancel the read operation, it would be better.
 			s.conn.SetReadDeadline(time.Now().Add(time.Millisecond * 500))
 
-			m, _, from, err = s.conn.ReadFrom()
+			var (
+				n  int
+				cm *ipv6.ControlMessage
+			)
+
+			// We don't use ReadFrom since it doesn't expose the ICMP code
+			n, cm, from, err = s.conn.ReadRaw(b)
 			if err != nil {
 				if os.IsTimeout(err) {
 					continue
@@ -94,11 +218,137 @@ func (s *sock) recvRS(ctx context.Context) (*ndp.RouterSolicitation, netip.Addr,
 				return
 			}
 
-			if m.Type() != ipv6.ICMPTypeRouterSolicitation {
+			// Ignore the message sent by ourselves
+			if from == s.addr {
+				continue
+			}
+
+			if n < 1 || ipv6.ICMPType(b[0]) != ipv6.ICMPTypeRouterSolicitation {
 				// Ignore non-RS message and retry
 				continue
 			}
 
+			m, perr := parseRS(b[:n])
+			if perr != nil {
+				err = fmt.Errorf("%w: %w", errRSMalformed, perr)
+				return
+			}
+
+			// The hop limit is unknown without the control message.
+			// Treat it as invalid.
+			hopLimit := -1
+			if cm != nil {
+				hopLimit = cm.HopLimit
+			}
+
+			rs = m
+			err = validateRS(hopLimit, b[1], rs, from)
+
+			return
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, netip.Addr{}, ctx.Err()
+	case <-ch:
+	}
+
+	if err != nil {
+		return nil, netip.Addr{}, err
+	}
+
+	return rs, from, nil
+}
+
+func (s *sock) sendRaw(ctx context.Context, dst netip.Addr, b []byte) error {
+	var err error
+
+	raw := s.raw
+	if raw == nil {
+		return errRawClosed
+	}
+
+	src := s.addr
+	if s.srcAddr.IsValid() {
+		src = s.srcAddr
+	} else if s.virtualAddr.IsValid() {
+		src = s.virtualAddr
+	}
+
+	cm := &ipv6.ControlMessage{
+		HopLimit: ndp.HopLimit,
+		Src:      src.AsSlice(),
+		IfIndex:  s.iface.Index,
+	}
+
+	ch := make(chan any)
+
+	go func() {
+		defer close(ch)
+		raw.SetWriteDeadline(time.Now().Add(time.Second * 2))
+		_, err = raw.WriteTo(b, cm, &net.IPAddr{IP: dst.AsSlice(), Zone: s.iface.Name})
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-ch:
+	}
+
+	return err
+}
+
+func (s *sock) recvCPS(ctx context.Context) (*certPathSolicitation, netip.Addr, error) {
+	var (
+		cps  *certPathSolicitation
+		from netip.Addr
+		err  error
+	)
+
+	// The connection may be closed after the context is done
+	raw := s.raw
+	if raw == nil {
+		return nil, netip.Addr{}, errRawClosed
+	}
+
+	ch := make(chan any)
+
+	go func() {
+		defer close(ch)
+
+		b := make([]byte, s.iface.MTU)
+
+		for {
+			raw.SetReadDeadline(time.Now().Add(time.Millisecond * 500))
+
+			n, cm, src, rerr := raw.ReadFrom(b)
+			if rerr != nil {
+				if os.IsTimeout(rerr) {
+					continue
+				}
+				err = rerr
+				return
+			}
+
+			// The connection receives the CPS from all interfaces
+			if cm == nil || cm.IfIndex != s.iface.Index {
+				continue
+			}
+
+			ipAddr, ok := src.(*net.IPAddr)
+			if !ok {
+				continue
+			}
+			from, _ = netip.AddrFromSlice(ipAddr.IP)
+			from = from.WithZone(s.iface.Name)
+
+			if cm.HopLimit != ndp.HopLimit {
+				err = fmt.Errorf("%w: invalid hop limit %d", errCPSMalformed, cm.HopLimit)
+				return
+			}
+
+			cps, err = parseCPS(b[:n])
 			return
 		}
 	}()
@@ -113,9 +363,157 @@ func (s *sock) recvRS(ctx context.Context) (*ndp.RouterSolicitation, netip.Addr,
 		return nil, netip.Addr{}, err
 	}
 
-	return m.(*ndp.RouterSolicitation), from, nil
+	return cps, from, nil
+}
+
+// setSourceAddr assigns the link-local address to the interface and uses it
+// as the source address of the raw messages. The address previously set is
+// removed. The Duplicate Address Detection is skipped so that the address
+// can be used immediately. If the address is invalid, the link-local
+// address of the kernel is used.
+func (s *sock) setSourceAddr(addr netip.Addr) error {
+	if addr == s.srcAddr {
+		return nil
+	}
+
+	link, err := netlink.LinkByIndex(s.iface.Index)
+	if err != nil {
+		return err
+	}
+
+	if s.srcAddr.IsValid() {
+		if err := delLinkLocalAddr(link, s.srcAddr); err != nil {
+			return err
+		}
+		s.srcAddr = netip.Addr{}
+	}
+
+	if addr.IsValid() {
+		if err := addLinkLocalAddr(link, addr); err != nil {
+			return err
+		}
+		s.srcAddr = addr
+	}
+
+	return nil
+}
+
+func (s *sock) setVirtualSourceAddr(addr netip.Addr) {
+	s.virtualAddr = addr
+}
+
+func linkLocalNetlinkAddr(addr netip.Addr) *netlink.Addr {
+	return &netlink.Addr{
+		IPNet: &net.IPNet{
+			IP:   net.IP(addr.AsSlice()),
+			Mask: net.CIDRMask(64, 128),
+		},
+	}
+}
+
+// addLinkLocalAddr assigns the link-local address to the link. The Duplicate
+// Address Detection is skipped so that the address can be used immediately.
+func addLinkLocalAddr(link netlink.Link, addr netip.Addr) error {
+	nladdr := linkLocalNetlinkAddr(addr)
+	nladdr.Flags = unix.IFA_F_NODAD
+	return netlink.AddrReplace(link, nladdr)
+}
+
+// delLinkLocalAddr removes the link-local address from the link. It's not an
+// error if the address is already removed.
+func delLinkLocalAddr(link netlink.Link, addr netip.Addr) error {
+	if err := netlink.AddrDel(link, linkLocalNetlinkAddr(addr)); err != nil && !errors.Is(err, unix.EADDRNOTAVAIL) {
+		return err
+	}
+	return nil
 }
 
 func (s *sock) close() {
+	// Best effort. The address will be replaced next time anyway.
+	s.setSourceAddr(netip.Addr{})
+	s.closeRaw()
 	s.conn.Close()
 }
+
+// parseRS parses the RS. mdlayher/ndp can't parse the Source Link-Layer
+// Address option other than 48-bit (e.g. InfiniBand), so such options are cut
+// out before parsing and appended to the result as ndp.RawOption.
+func parseRS(b []byte) (*ndp.RouterSolicitation, error) {
+	// ICMP header and the Reserved field
+	const rsHeaderLength = 8
+
+	if len(b) < rsHeaderLength {
+		return nil, errors.New("message is too short")
+	}
+
+	stripped := slices.Clone(b[:rsHeaderLength])
+	raws := []ndp.Option{}
+	for off := rsHeaderLength; off < len(b); {
+		if len(b)-off < 2 || b[off+1] == 0 || len(b)-off < int(b[off+1])*8 {
+			return nil, errors.New("invalid option length")
+		}
+		option := b[off : off+int(b[off+1])*8]
+		if option[0] == byte(ndp.Source) && len(option) != 8 {
+			raws = append(raws, &ndp.RawOption{
+				Type:   option[0],
+				Length: option[1],
+				Value:  slices.Clone(option[2:]),
+			})
+		} else {
+			stripped = append(stripped, option...)
+		}
+		off += len(option)
+	}
+
+	m, err := ndp.ParseMessage(stripped)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, ok := m.(*ndp.RouterSolicitation)
+	if !ok {
+		return nil, errors.New("not an RS")
+	}
+	rs.Options = append(rs.Options, raws...)
+
+	return rs, nil
+}
+
+// validateRS validates the received RS as described in RFC4861 Section 6.1.1.
+// The checks done by the kernel (checksum, ICMP length) and the ones done by
+// the parser (option length) are omitted.
+func validateRS(hopLimit int, code uint8, rs *ndp.RouterSolicitation, from netip.Addr) error {
+	if hopLimit != ndp.HopLimit {
+		return fmt.Errorf("%w: %d", errRSInvalidHopLimit, hopLimit)
+	}
+
+	if code != 0 {
+		return fmt.Errorf("%w: %d", errRSInvalidCode, code)
+	}
+
+	if from.WithZone("").IsUnspecified() && sourceLinkLayerAddress(rs) != nil {
+		return errRSInvalidSLLA
+	}
+
+	return nil
+}
+
+// sourceLinkLayerAddress returns the value of the Source Link-Layer Address
+// option of the RS. Returns nil if the option doesn't exist. For the
+// link-layer addresses other than 48-bit, the value may contain the reserved
+// bytes and padding.
+func sourceLinkLayerAddress(rs *ndp.RouterSolicitation) net.HardwareAddr {
+	for _, option := range rs.Options {
+		switch opt := option.(type) {
+		case *ndp.LinkLayerAddress:
+			if opt.Direction == ndp.Source {
+				return opt.Addr
+			}
+		case *ndp.RawOption:
+			if opt.Type == byte(ndp.Source) {
+				return net.HardwareAddr(opt.Value)
+			}
+		}
+	}
+	return nil
+}
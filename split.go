@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mdlayher/ndp"
+	"golang.org/x/net/ipv6"
+)
+
+// maxRALength returns the maximum length of the RA including the ICMP header
+// fitting in the link MTU. Returns zero when the MTU is unknown.
+func maxRALength(mtu int) int {
+	if mtu <= ipv6.HeaderLen {
+		return 0
+	}
+	return mtu - ipv6.HeaderLen
+}
+
+// optionsLength returns the encoded length of the options
+func optionsLength(options []ndp.Option) (int, error) {
+	b, err := ndp.MarshalMessage(&ndp.RouterAdvertisement{Options: options})
+	if err != nil {
+		return 0, err
+	}
+	return len(b) - raHeaderLength, nil
+}
+
+// splitRAMsg splits the RA into the multiple RAs each of which fits in
+// maxLength bytes as RFC4861 Section 6.2.3 allows. Every RA carries the same
+// header and the Source Link-Layer Address option. The other options are
+// packed into the RAs in the original order, so the options created first
+// (MTU, prefixes, routes, and so on) always go to the first RA. An option
+// which doesn't fit in an RA even alone is sent alone. The RA is returned as
+// is when it fits or maxLength is zero.
+func splitRAMsg(msg *ndp.RouterAdvertisement, maxLength int) ([]*ndp.RouterAdvertisement, error) {
+	if maxLength <= 0 {
+		return []*ndp.RouterAdvertisement{msg}, nil
+	}
+
+	b, err := ndp.MarshalMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) <= maxLength {
+		return []*ndp.RouterAdvertisement{msg}, nil
+	}
+
+	// Find the length of each option from the encoded message. The
+	// Length field is in units of 8 bytes.
+	lengths := []int{}
+	for off := raHeaderLength; off+1 < len(b); off += lengths[len(lengths)-1] {
+		lengths = append(lengths, int(b[off+1])*8)
+	}
+	if len(lengths) != len(msg.Options) {
+		return nil, fmt.Errorf("failed to find the option boundaries")
+	}
+
+	common := []ndp.Option{}
+	commonLength := raHeaderLength
+	rest := []ndp.Option{}
+	restLengths := []int{}
+	for i, option := range msg.Options {
+		if isSourceLinkLayerAddressOption(option) {
+			common = append(common, option)
+			commonLength += lengths[i]
+			continue
+		}
+		rest = append(rest, option)
+		restLengths = append(restLengths, lengths[i])
+	}
+
+	if len(rest) == 0 {
+		return []*ndp.RouterAdvertisement{msg}, nil
+	}
+
+	msgs := []*ndp.RouterAdvertisement{}
+	var cur *ndp.RouterAdvertisement
+	curLength := 0
+	for i, option := range rest {
+		if cur == nil || curLength+restLengths[i] > maxLength {
+			m := *msg
+			m.Options = slices.Clone(common)
+			cur = &m
+			curLength = commonLength
+			msgs = append(msgs, cur)
+		}
+		cur.Options = append(cur.Options, option)
+		curLength += restLengths[i]
+	}
+
+	return msgs, nil
+}
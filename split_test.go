@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of go-ra
+
+package ra
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/ndp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRAMsg(t *testing.T) {
+	slla := &ndp.LinkLayerAddress{
+		Direction: ndp.Source,
+		Addr:      net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66},
+	}
+
+	// 32 bytes each
+	prefix := func(s string) *ndp.PrefixInformation {
+		p := netip.MustParsePrefix(s)
+		return &ndp.PrefixInformation{
+			PrefixLength:      uint8(p.Bits()),
+			OnLink:            true,
+			ValidLifetime:     time.Hour,
+			PreferredLifetime: time.Minute,
+			Prefix:            p.Addr(),
+		}
+	}
+
+	// 88 bytes
+	rdnss := &ndp.RecursiveDNSServer{
+		Lifetime: time.Hour,
+		Servers: []netip.Addr{
+			netip.MustParseAddr("2001:db8::1"),
+			netip.MustParseAddr("2001:db8::2"),
+			netip.MustParseAddr("2001:db8::3"),
+			netip.MustParseAddr("2001:db8::4"),
+			netip.MustParseAddr("2001:db8::5"),
+		},
+	}
+
+	header := ndp.RouterAdvertisement{
+		CurrentHopLimit: 64,
+		RouterLifetime:  time.Second * 1800,
+	}
+
+	withOptions := func(options ...ndp.Option) *ndp.RouterAdvertisement {
+		msg := header
+		msg.Options = options
+		return &msg
+	}
+
+	tests := []struct {
+		name      string
+		msg       *ndp.RouterAdvertisement
+		maxLength int
+		expected  []*ndp.RouterAdvertisement
+	}{
+		{
+			name:      "No limit",
+			msg:       withOptions(slla, prefix("2001:db8:1::/64"), prefix("2001:db8:2::/64")),
+			maxLength: 0,
+			expected: []*ndp.RouterAdvertisement{
+				withOptions(slla, prefix("2001:db8:1::/64"), prefix("2001:db8:2::/64")),
+			},
+		},
+		{
+			name:      "Fits",
+			msg:       withOptions(slla, prefix("2001:db8:1::/64"), prefix("2001:db8:2::/64")),
+			maxLength: 16 + 8 + 32*2,
+			expected: []*ndp.RouterAdvertisement{
+				withOptions(slla, prefix("2001:db8:1::/64"), prefix("2001:db8:2::/64")),
+			},
+		},
+		{
+			name: "Split in order with SLLA in every RA",
+			msg: withOptions(
+				prefix("2001:db8:1::/64"), slla, prefix("2001:db8:2::/64"),
+				prefix("2001:db8:3::/64"), prefix("2001:db8:4::/64"), prefix("2001:db8:5::/64"),
+			),
+			maxLength: 16 + 8 + 32*2,
+			expected: []*ndp.RouterAdvertisement{
+				withOptions(slla, prefix("2001:db8:1::/64"), prefix("2001:db8:2::/64")),
+				withOptions(slla, prefix("2001:db8:3::/64"), prefix("2001:db8:4::/64")),
+				withOptions(slla, prefix("2001:db8:5::/64")),
+			},
+		},
+		{
+			name:      "Oversized option is sent alone",
+			msg:       withOptions(slla, prefix("2001:db8:1::/64"), rdnss, prefix("2001:db8:2::/64")),
+			maxLength: 16 + 8 + 32*2,
+			expected: []*ndp.RouterAdvertisement{
+				withOptions(slla, prefix("2001:db8:1::/64")),
+				withOptions(slla, rdnss),
+				withOptions(slla, prefix("2001:db8:2::/64")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs, err := splitRAMsg(tt.msg, tt.maxLength)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, msgs)
+		})
+	}
+}
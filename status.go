@@ -19,6 +19,26 @@ const (
 	Failing = "Failing"
 	// Stopped means the router advertisement is stopped
 	Stopped = "Stopped"
+	// Backup means the router advertisement is paused because the
+	// virtual link-local address isn't assigned to the interface (e.g.
+	// the virtual router is the backup)
+	Backup = "Backup"
+)
+
+// Possible state of the tracked route
+const (
+	// RoutePresent means the tracked route exists
+	RoutePresent = "Present"
+	// RouteAbsent means the tracked route doesn't exist
+	RouteAbsent = "Absent"
+)
+
+// Possible health state of the router
+const (
+	// Healthy means the health check passes
+	Healthy = "Healthy"
+	// Unhealthy means the health check fails
+	Unhealthy = "Unhealthy"
 )
 
 // InterfaceStatus represents the interface-specific status of the Daemon
@@ -40,4 +60,101 @@ type InterfaceStatus struct {
 
 	// Number of sent unsolicited router advertisements
 	TxUnsolicitedRA int `yaml:"txUnsolicitedRA" json:"txUnsolicitedRA"`
+
+	// Number of router solicitations dropped by the rate limit
+	RateLimitedRS int `yaml:"rateLimitedRS" json:"rateLimitedRS"`
+
+	// Number of router solicitations answered together with the other
+	// router solicitations or the unsolicited router advertisement
+	CoalescedRS int `yaml:"coalescedRS" json:"coalescedRS"`
+
+	// Number of router solicitations dropped because they couldn't be
+	// parsed
+	MalformedRS int `yaml:"malformedRS" json:"malformedRS"`
+
+	// Number of router solicitations dropped because the hop limit is not
+	// 255
+	InvalidHopLimitRS int `yaml:"invalidHopLimitRS" json:"invalidHopLimitRS"`
+
+	// Number of router solicitations dropped because the ICMP code is not 0
+	InvalidCodeRS int `yaml:"invalidCodeRS" json:"invalidCodeRS"`
+
+	// Number of router solicitations dropped because they are sent from
+	// the unspecified address with the Source Link-Layer Address option
+	InvalidSLLARS int `yaml:"invalidSLLARS" json:"invalidSLLARS"`
+
+	// Scheduled time of the next unsolicited router advertisement in Unix
+	// time. Zero if no advertisement is scheduled.
+	NextUnsolicitedRA int64 `yaml:"nextUnsolicitedRA" json:"nextUnsolicitedRA"`
+
+	// State of the route tracked by TrackRoute. One of "Present" or
+	// "Absent". Empty if no route is tracked.
+	TrackedRoute string `yaml:"trackedRoute,omitempty" json:"trackedRoute,omitempty"`
+
+	// Prefixes derived from the addresses of the interface or the upstream
+	// interfaces by the auto mode prefixes (::/N). Empty if no prefix is
+	// derived.
+	DerivedPrefixes []*DerivedPrefixStatus `yaml:"derivedPrefixes,omitempty" json:"derivedPrefixes,omitempty"`
+
+	// Destinations of the routes derived from the routing table by
+	// RouteSource, including the ones overridden by Routes. Empty if no
+	// route is derived.
+	SourcedRoutes []string `yaml:"sourcedRoutes,omitempty" json:"sourcedRoutes,omitempty"`
+
+	// Result of the health check. Nil if no health check is performed.
+	HealthCheck *HealthCheckStatus `yaml:"healthCheck,omitempty" json:"healthCheck,omitempty"`
+
+	// Options removed by the reload and being advertised with zero
+	// lifetimes
+	DeprecatedOptions []*DeprecatedOptionStatus `yaml:"deprecatedOptions,omitempty" json:"deprecatedOptions,omitempty"`
+}
+
+// DerivedPrefixStatus represents the prefix derived from the address by the
+// auto mode prefix
+type DerivedPrefixStatus struct {
+	// The derived prefix
+	Prefix string `yaml:"prefix" json:"prefix"`
+
+	// Name of the interface the address is assigned to. Either the
+	// advertising interface or the upstream interface.
+	Interface string `yaml:"interface" json:"interface"`
+
+	// The address the prefix is derived from with its prefix length
+	Address string `yaml:"address" json:"address"`
+}
+
+// HealthCheckStatus represents the result of the health check
+type HealthCheckStatus struct {
+	// Health state of the router. One of "Healthy" or "Unhealthy".
+	State string `yaml:"state" json:"state"`
+
+	// Error of the last probe. Empty if the last probe succeeded.
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	// Number of the consecutive successful probes
+	ConsecutiveSuccesses int `yaml:"consecutiveSuccesses" json:"consecutiveSuccesses"`
+
+	// Number of the consecutive failed probes
+	ConsecutiveFailures int `yaml:"consecutiveFailures" json:"consecutiveFailures"`
+
+	// Time of the last probe in Unix time. Zero if no probe is done yet.
+	LastProbe int64 `yaml:"lastProbe" json:"lastProbe"`
+}
+
+// DeprecatedOptionStatus represents the option removed by the reload and
+// being advertised with zero lifetimes
+type DeprecatedOptionStatus struct {
+	// Type of the option. One of "Prefix", "Route", "RDNSS", "DNSSL",
+	// or "NAT64Prefix".
+	Type string `yaml:"type" json:"type"`
+
+	// Prefix, address, or domain name of the option
+	Value string `yaml:"value" json:"value"`
+
+	// FQDN of the PvD the option is nested in. Empty for the option
+	// outside of any PvD.
+	PvD string `yaml:"pvd,omitempty" json:"pvd,omitempty"`
+
+	// Time to stop advertising the option in Unix time
+	Until int64 `yaml:"until" json:"until"`
 }
@@ -1,4 +1,4 @@
-// Code generated by deepcopy-gen Config Status InterfaceConfig InterfaceStatus PrefixConfig RouteConfig RDNSSConfig DNSSLConfig; DO NOT EDIT.
+// Code generated by deepcopy-gen Config Status InterfaceConfig InterfaceStatus PrefixConfig RouteConfig RouteSourceConfig RDNSSConfig DNSSLConfig NAT64PrefixConfig DNRConfig PvDConfig RawOptionConfig SENDConfig VirtualRouterConfig TrackRouteConfig HealthCheckConfig DerivedPrefixStatus HealthCheckStatus DeprecatedOptionStatus; DO NOT EDIT.
 
 package ra
 
@@ -35,6 +35,30 @@ func (o *Status) deepCopy() *Status {
 // deepCopy generates a deep copy of *InterfaceConfig
 func (o *InterfaceConfig) deepCopy() *InterfaceConfig {
 	var cp InterfaceConfig = *o
+	if o.InitialRACount != nil {
+		cp.InitialRACount = new(int)
+		*cp.InitialRACount = *o.InitialRACount
+	}
+	if o.FinalRACount != nil {
+		cp.FinalRACount = new(int)
+		*cp.FinalRACount = *o.FinalRACount
+	}
+	if o.DeprecationPeriodSeconds != nil {
+		cp.DeprecationPeriodSeconds = new(int)
+		*cp.DeprecationPeriodSeconds = *o.DeprecationPeriodSeconds
+	}
+	if o.MinDelayBetweenRAsMilliseconds != nil {
+		cp.MinDelayBetweenRAsMilliseconds = new(int)
+		*cp.MinDelayBetweenRAsMilliseconds = *o.MinDelayBetweenRAsMilliseconds
+	}
+	if o.RSRateLimitPerSecond != nil {
+		cp.RSRateLimitPerSecond = new(int)
+		*cp.RSRateLimitPerSecond = *o.RSRateLimitPerSecond
+	}
+	if o.Clients != nil {
+		cp.Clients = make([]string, len(o.Clients))
+		copy(cp.Clients, o.Clients)
+	}
 	if o.Prefixes != nil {
 		cp.Prefixes = make([]*PrefixConfig, len(o.Prefixes))
 		copy(cp.Prefixes, o.Prefixes)
@@ -53,6 +77,9 @@ func (o *InterfaceConfig) deepCopy() *InterfaceConfig {
 			}
 		}
 	}
+	if o.RouteSource != nil {
+		cp.RouteSource = o.RouteSource.deepCopy()
+	}
 	if o.RDNSSes != nil {
 		cp.RDNSSes = make([]*RDNSSConfig, len(o.RDNSSes))
 		copy(cp.RDNSSes, o.RDNSSes)
@@ -71,12 +98,85 @@ func (o *InterfaceConfig) deepCopy() *InterfaceConfig {
 			}
 		}
 	}
+	if o.NAT64Prefixes != nil {
+		cp.NAT64Prefixes = make([]*NAT64PrefixConfig, len(o.NAT64Prefixes))
+		copy(cp.NAT64Prefixes, o.NAT64Prefixes)
+		for i2 := range o.NAT64Prefixes {
+			if o.NAT64Prefixes[i2] != nil {
+				cp.NAT64Prefixes[i2] = o.NAT64Prefixes[i2].deepCopy()
+			}
+		}
+	}
+	if o.DNRs != nil {
+		cp.DNRs = make([]*DNRConfig, len(o.DNRs))
+		copy(cp.DNRs, o.DNRs)
+		for i2 := range o.DNRs {
+			if o.DNRs[i2] != nil {
+				cp.DNRs[i2] = o.DNRs[i2].deepCopy()
+			}
+		}
+	}
+	if o.PvDs != nil {
+		cp.PvDs = make([]*PvDConfig, len(o.PvDs))
+		copy(cp.PvDs, o.PvDs)
+		for i2 := range o.PvDs {
+			if o.PvDs[i2] != nil {
+				cp.PvDs[i2] = o.PvDs[i2].deepCopy()
+			}
+		}
+	}
+	if o.RawOptions != nil {
+		cp.RawOptions = make([]*RawOptionConfig, len(o.RawOptions))
+		copy(cp.RawOptions, o.RawOptions)
+		for i2 := range o.RawOptions {
+			if o.RawOptions[i2] != nil {
+				cp.RawOptions[i2] = o.RawOptions[i2].deepCopy()
+			}
+		}
+	}
+	if o.SEND != nil {
+		cp.SEND = o.SEND.deepCopy()
+	}
+	if o.VirtualRouter != nil {
+		cp.VirtualRouter = o.VirtualRouter.deepCopy()
+	}
+	if o.TrackRoute != nil {
+		cp.TrackRoute = o.TrackRoute.deepCopy()
+	}
+	if o.HealthCheck != nil {
+		cp.HealthCheck = o.HealthCheck.deepCopy()
+	}
 	return &cp
 }
 
 // deepCopy generates a deep copy of *InterfaceStatus
 func (o *InterfaceStatus) deepCopy() *InterfaceStatus {
 	var cp InterfaceStatus = *o
+	if o.DerivedPrefixes != nil {
+		cp.DerivedPrefixes = make([]*DerivedPrefixStatus, len(o.DerivedPrefixes))
+		copy(cp.DerivedPrefixes, o.DerivedPrefixes)
+		for i2 := range o.DerivedPrefixes {
+			if o.DerivedPrefixes[i2] != nil {
+				cp.DerivedPrefixes[i2] = o.DerivedPrefixes[i2].deepCopy()
+			}
+		}
+	}
+	if o.SourcedRoutes != nil {
+		cp.SourcedRoutes = make([]string, len(o.SourcedRoutes))
+		copy(cp.SourcedRoutes, o.SourcedRoutes)
+	}
+	if o.HealthCheck != nil {
+		cp.HealthCheck = o.HealthCheck.deepCopy()
+	}
+	if o.DeprecatedOptions != nil {
+		cp.DeprecatedOptions = make([]*DeprecatedOptionStatus, len(o.DeprecatedOptions))
+		copy(cp.DeprecatedOptions, o.DeprecatedOptions)
+		for i2 := range o.DeprecatedOptions {
+			if o.DeprecatedOptions[i2] != nil {
+				cp.DeprecatedOptions[i2] = o.DeprecatedOptions[i2].deepCopy()
+			}
+		}
+	}
 	return &cp
 }
 
@@ -100,6 +200,20 @@ func (o *RouteConfig) deepCopy() *RouteConfig {
 	return &cp
 }
 
+// deepCopy generates a deep copy of *RouteSourceConfig
+func (o *RouteSourceConfig) deepCopy() *RouteSourceConfig {
+	var cp RouteSourceConfig = *o
+	if o.Protocols != nil {
+		cp.Protocols = make([]string, len(o.Protocols))
+		copy(cp.Protocols, o.Protocols)
+	}
+	if o.Prefixes != nil {
+		cp.Prefixes = make([]string, len(o.Prefixes))
+		copy(cp.Prefixes, o.Prefixes)
+	}
+	return &cp
+}
+
 // deepCopy generates a deep copy of *RDNSSConfig
 func (o *RDNSSConfig) deepCopy() *RDNSSConfig {
 	var cp RDNSSConfig = *o
@@ -119,3 +233,125 @@ func (o *DNSSLConfig) deepCopy() *DNSSLConfig {
 	}
 	return &cp
 }
+
+// deepCopy generates a deep copy of *NAT64PrefixConfig
+func (o *NAT64PrefixConfig) deepCopy() *NAT64PrefixConfig {
+	var cp NAT64PrefixConfig = *o
+	if o.LifetimeSeconds != nil {
+		cp.LifetimeSeconds = new(int)
+		*cp.LifetimeSeconds = *o.LifetimeSeconds
+	}
+	return &cp
+}
+
+// deepCopy generates a deep copy of *DNRConfig
+func (o *DNRConfig) deepCopy() *DNRConfig {
+	var cp DNRConfig = *o
+	if o.Addresses != nil {
+		cp.Addresses = make([]string, len(o.Addresses))
+		copy(cp.Addresses, o.Addresses)
+	}
+	if o.ALPNs != nil {
+		cp.ALPNs = make([]string, len(o.ALPNs))
+		copy(cp.ALPNs, o.ALPNs)
+	}
+	return &cp
+}
+
+// deepCopy generates a deep copy of *PvDConfig
+func (o *PvDConfig) deepCopy() *PvDConfig {
+	var cp PvDConfig = *o
+	if o.Prefixes != nil {
+		cp.Prefixes = make([]*PrefixConfig, len(o.Prefixes))
+		copy(cp.Prefixes, o.Prefixes)
+		for i2 := range o.Prefixes {
+			if o.Prefixes[i2] != nil {
+				cp.Prefixes[i2] = o.Prefixes[i2].deepCopy()
+			}
+		}
+	}
+	if o.Routes != nil {
+		cp.Routes = make([]*RouteConfig, len(o.Routes))
+		copy(cp.Routes, o.Routes)
+		for i2 := range o.Routes {
+			if o.Routes[i2] != nil {
+				cp.Routes[i2] = o.Routes[i2].deepCopy()
+			}
+		}
+	}
+	if o.RDNSSes != nil {
+		cp.RDNSSes = make([]*RDNSSConfig, len(o.RDNSSes))
+		copy(cp.RDNSSes, o.RDNSSes)
+		for i2 := range o.RDNSSes {
+			if o.RDNSSes[i2] != nil {
+				cp.RDNSSes[i2] = o.RDNSSes[i2].deepCopy()
+			}
+		}
+	}
+	if o.DNSSLs != nil {
+		cp.DNSSLs = make([]*DNSSLConfig, len(o.DNSSLs))
+		copy(cp.DNSSLs, o.DNSSLs)
+		for i2 := range o.DNSSLs {
+			if o.DNSSLs[i2] != nil {
+				cp.DNSSLs[i2] = o.DNSSLs[i2].deepCopy()
+			}
+		}
+	}
+	return &cp
+}
+
+// deepCopy generates a deep copy of *RawOptionConfig
+func (o *RawOptionConfig) deepCopy() *RawOptionConfig {
+	var cp RawOptionConfig = *o
+	return &cp
+}
+
+// deepCopy generates a deep copy of *SENDConfig
+func (o *SENDConfig) deepCopy() *SENDConfig {
+	var cp SENDConfig = *o
+	if o.Certificates != nil {
+		cp.Certificates = make([]string, len(o.Certificates))
+		copy(cp.Certificates, o.Certificates)
+	}
+	return &cp
+}
+
+// deepCopy generates a deep copy of *VirtualRouterConfig
+func (o *VirtualRouterConfig) deepCopy() *VirtualRouterConfig {
+	var cp VirtualRouterConfig = *o
+	return &cp
+}
+
+// deepCopy generates a deep copy of *TrackRouteConfig
+func (o *TrackRouteConfig) deepCopy() *TrackRouteConfig {
+	var cp TrackRouteConfig = *o
+	return &cp
+}
+
+// deepCopy generates a deep copy of *HealthCheckConfig
+func (o *HealthCheckConfig) deepCopy() *HealthCheckConfig {
+	var cp HealthCheckConfig = *o
+	if o.Command != nil {
+		cp.Command = make([]string, len(o.Command))
+		copy(cp.Command, o.Command)
+	}
+	return &cp
+}
+
+// deepCopy generates a deep copy of *DerivedPrefixStatus
+func (o *DerivedPrefixStatus) deepCopy() *DerivedPrefixStatus {
+	var cp DerivedPrefixStatus = *o
+	return &cp
+}
+
+// deepCopy generates a deep copy of *HealthCheckStatus
+func (o *HealthCheckStatus) deepCopy() *HealthCheckStatus {
+	var cp HealthCheckStatus = *o
+	return &cp
+}
+
+// deepCopy generates a deep copy of *DeprecatedOptionStatus
+func (o *DeprecatedOptionStatus) deepCopy() *DeprecatedOptionStatus {
+	var cp DeprecatedOptionStatus = *o
+	return &cp
+}